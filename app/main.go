@@ -3,22 +3,39 @@ package main
 // The path to your project is declared in your
 // go.mod file followed by the directory
 import (
+	"errors"
 	stuff "example/project/mypackage"
+	"example/project/mypackage/numfmt"
 	"fmt"
 	"log"
+	"os"
 	"reflect"
 )
 
 func main() {
-	fmt.Println("Hello", stuff.Name)
+	// A name on the command line is greeted instead
+	if len(os.Args) > 1 {
+		stuff.Name = os.Args[1]
+	}
+	fmt.Println("Hello", stuff.GreetName())
 	intArr := []int{2, 3, 5, 7, 11}
 	strArr := stuff.IntArrToStrArr(intArr)
 	fmt.Println(strArr)
 	fmt.Println(reflect.TypeOf(strArr))
 
+	// Converting back reports every bad value
+	_, err := stuff.StrArrToIntArr([]string{"2", "three", "5", "seven"})
+	fmt.Println(err)
+
+	// Numbers written for people
+	fmt.Println(numfmt.English.Int(1234567), numfmt.German.Float(1234.5, 2),
+		numfmt.English.Compact(1234), numfmt.Ordinal(21))
+	roman, _ := numfmt.Roman(1974)
+	fmt.Println(roman, "is", numfmt.Words(1974))
+
 	// Demonstrating encapsulation
 	date := stuff.Date{}
-	err := date.SetMonth(12)
+	err = date.SetMonth(12)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -30,6 +47,20 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Printf("1st Day : %d/%d/%d\n",
-		date.Month(), date.Day(), date.Year())
+	fmt.Println("1st Day :", date.Format("MM/DD/YYYY"))
+	fmt.Println("ISO 8601 :", date)
+
+	// Dates can be read back from text
+	parsed, err := stuff.ParseLayout("MMMM D, YYYY", "December 21, 1974")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Same day :", parsed.Equal(date))
+
+	// NewDate checks the whole date at once
+	_, err = stuff.NewDate(2023, 2, 29)
+	var dateErr *stuff.DateError
+	if errors.As(err, &dateErr) {
+		fmt.Println("Bad", dateErr.Field, ":", err)
+	}
 }
@@ -0,0 +1,163 @@
+package stuff
+
+import "fmt"
+
+// Age is the time between two dates in whole years,
+// months and days, like people give their age
+type Age struct {
+	Years  int
+	Months int
+	Days   int
+}
+
+// Return the age like 49 years, 11 months, 30 days
+func (a Age) String() string {
+	return fmt.Sprintf("%d years, %d months, %d days", a.Years, a.Months, a.Days)
+}
+
+// LeapDayRule says when people born on 29 February
+// have their birthday in other years. It is passed to
+// Age and Next, the zero value is LeapFeb28
+type LeapDayRule int
+
+const (
+	// The last day of February, which is the law in
+	// places like New Zealand
+	LeapFeb28 LeapDayRule = iota
+	// The day after 28 February, which is the law in
+	// places like England
+	LeapMar1
+)
+
+// Return the birthday of d in a year. It isn't
+// checked against a policy
+func (d Date) birthdayIn(year int, leap LeapDayRule) Date {
+	if d.month == 2 && d.day == 29 && !isLeap(year) {
+		if leap == LeapMar1 {
+			return Date{day: 1, month: 3, year: year}
+		}
+		return Date{day: 28, month: 2, year: year}
+	}
+	return Date{day: d.day, month: d.month, year: year}
+}
+
+// Return the day some months after the birthday of d
+// in a year. It has the same day of the month as d, or
+// the last day of the month if that is too short
+func (d Date) monthDayAfter(year, months int) Date {
+	total := year*12 + d.month - 1 + months
+	y, m := total/12, total%12+1
+	day := d.day
+	if day > daysIn(y, m) {
+		day = daysIn(y, m)
+	}
+	return Date{day: day, month: m, year: y}
+}
+
+// Return how old someone born on d is on another day.
+// A year is counted on each birthday and a month on
+// each day with the birthday's day of the month. The
+// leap day rule picks the birthday of 29 February
+func (d Date) Age(on Date, leap LeapDayRule) (Age, error) {
+	if on.Before(d) {
+		return Age{}, fmt.Errorf("%v is before the birth date %v", on, d)
+	}
+	var age Age
+	age.Years = on.year - d.year
+	birthday := d.birthdayIn(on.year, leap)
+	if on.Before(birthday) {
+		age.Years--
+		birthday = d.birthdayIn(on.year-1, leap)
+	}
+	// With LeapMar1 the twelfth month can come before
+	// the birthday, so stop at eleven
+	for age.Months < 11 && !on.Before(d.monthDayAfter(birthday.year, age.Months+1)) {
+		age.Months++
+	}
+	start := birthday
+	if age.Months > 0 {
+		start = d.monthDayAfter(birthday.year, age.Months)
+	}
+	age.Days = start.DaysBetween(on)
+	return age, nil
+}
+
+// Milestone is a range of ages whose birthdays are
+// important. To is NoLimit if every age from From on
+// counts
+type Milestone struct {
+	From int
+	To   int
+	Name string
+}
+
+// Milestones with no upper age
+const NoLimit = -1
+
+// Milestones are the rules for important birthdays
+type Milestones []Milestone
+
+// The important birthdays from hellogo.go
+var ImportantBirthdays = Milestones{
+	{From: 1, To: 18, Name: "Childhood"},
+	{From: 21, To: 21, Name: "Coming of age"},
+	{From: 50, To: 50, Name: "Half century"},
+	{From: 65, To: NoLimit, Name: "Senior"},
+}
+
+// Return the milestone an age is part of
+func (rules Milestones) Match(age int) (Milestone, bool) {
+	for _, rule := range rules {
+		if age >= rule.From && (rule.To == NoLimit || age <= rule.To) {
+			return rule, true
+		}
+	}
+	return Milestone{}, false
+}
+
+// Birthday is an important birthday that is coming up
+type Birthday struct {
+	Age       int
+	Date      Date
+	Milestone Milestone
+}
+
+// Return the next important birthday on or after a day
+// for someone born on birth. The date uses the
+// Scheduling policy because it is usually in the
+// future. It returns false if there isn't one
+func (rules Milestones) Next(birth, from Date, leap LeapDayRule) (Birthday, bool) {
+	age := from.year - birth.year
+	if from.After(birth.birthdayIn(from.year, leap)) {
+		age++
+	}
+	for ; birth.year+age <= lastYear; age++ {
+		if age < 0 {
+			continue
+		}
+		rule, ok := rules.Match(age)
+		if !ok {
+			if !rules.anyFrom(age) {
+				return Birthday{}, false
+			}
+			continue
+		}
+		day := birth.birthdayIn(birth.year+age, leap)
+		date, err := Scheduling().NewDate(day.year, day.month, day.day)
+		if err != nil {
+			return Birthday{}, false
+		}
+		return Birthday{Age: age, Date: date, Milestone: rule}, true
+	}
+	return Birthday{}, false
+}
+
+// Check if any milestone covers an age or an older one
+func (rules Milestones) anyFrom(age int) bool {
+	for _, rule := range rules {
+		if rule.To == NoLimit || rule.To >= age {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package stuff
+
+import "testing"
+
+func TestAge(t *testing.T) {
+	tests := []struct {
+		birth, on string
+		leap      LeapDayRule
+		want      Age
+	}{
+		{"1974-12-21", "1974-12-21", LeapFeb28, Age{0, 0, 0}},
+		{"1974-12-21", "2024-12-20", LeapFeb28, Age{49, 11, 29}},
+		{"1974-12-21", "2024-12-21", LeapFeb28, Age{50, 0, 0}},
+		{"1974-12-21", "2025-01-20", LeapFeb28, Age{50, 0, 30}},
+		{"1974-12-21", "2025-01-21", LeapFeb28, Age{50, 1, 0}},
+		// Short months count as a whole month
+		{"2000-01-31", "2000-02-29", LeapFeb28, Age{0, 1, 0}},
+		{"2001-01-31", "2001-03-01", LeapFeb28, Age{0, 1, 1}},
+		{"2000-02-29", "2001-02-28", LeapFeb28, Age{1, 0, 0}},
+		{"2000-02-29", "2001-02-28", LeapMar1, Age{0, 11, 30}},
+		{"2000-02-29", "2001-03-01", LeapMar1, Age{1, 0, 0}},
+		{"2000-02-29", "2001-03-28", LeapMar1, Age{1, 0, 27}},
+		{"2000-02-29", "2001-03-29", LeapMar1, Age{1, 1, 0}},
+		{"2000-02-29", "2004-02-29", LeapMar1, Age{4, 0, 0}},
+		{"2000-02-29", "2004-02-28", LeapFeb28, Age{3, 11, 30}},
+	}
+	for _, test := range tests {
+		days := mustParse(t, test.birth, test.on)
+		got, err := days[0].Age(days[1], test.leap)
+		if err != nil {
+			t.Errorf("%s to %s: %v", test.birth, test.on, err)
+		} else if got != test.want {
+			t.Errorf("%s to %s with rule %d = %v, want %v", test.birth, test.on, test.leap, got, test.want)
+		}
+	}
+	days := mustParse(t, "2000-01-01", "1999-12-31")
+	if _, err := days[0].Age(days[1], LeapFeb28); err == nil {
+		t.Error("got an age before birth")
+	}
+}
+
+func TestMilestones(t *testing.T) {
+	for age, want := range map[int]bool{0: false, 1: true, 18: true, 19: false,
+		21: true, 22: false, 50: true, 64: false, 65: true, 99: true} {
+		if _, ok := ImportantBirthdays.Match(age); ok != want {
+			t.Errorf("Match(%d) = %v", age, ok)
+		}
+	}
+
+	tests := []struct {
+		birth, from string
+		age         int
+		date, name  string
+	}{
+		{"2010-06-01", "2024-05-31", 14, "2024-06-01", "Childhood"},
+		{"2010-06-01", "2024-06-01", 14, "2024-06-01", "Childhood"},
+		{"2005-06-01", "2024-06-02", 21, "2026-06-01", "Coming of age"},
+		{"1980-02-29", "2024-03-01", 50, "2030-02-28", "Half century"},
+		{"1959-12-21", "2024-12-22", 66, "2025-12-21", "Senior"},
+	}
+	for _, test := range tests {
+		days := mustParse(t, test.birth, test.from)
+		next, ok := ImportantBirthdays.Next(days[0], days[1], LeapFeb28)
+		if !ok {
+			t.Errorf("%s from %s: no milestone", test.birth, test.from)
+			continue
+		}
+		if next.Age != test.age || next.Date.String() != test.date || next.Milestone.Name != test.name {
+			t.Errorf("%s from %s = %d on %v (%s), want %d on %s (%s)", test.birth, test.from,
+				next.Age, next.Date, next.Milestone.Name, test.age, test.date, test.name)
+		}
+	}
+
+	days := mustParse(t, "1980-02-29", "2024-03-01")
+	if next, _ := ImportantBirthdays.Next(days[0], days[1], LeapMar1); next.Date.String() != "2030-03-01" {
+		t.Errorf("half century with LeapMar1 is on %v", next.Date)
+	}
+
+	teens := Milestones{{From: 13, To: 19, Name: "Teens"}}
+	days = mustParse(t, "2000-01-01", "2024-01-01")
+	if next, ok := teens.Next(days[0], days[1], LeapFeb28); ok {
+		t.Errorf("got %+v after the last milestone", next)
+	}
+}
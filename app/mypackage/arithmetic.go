@@ -0,0 +1,131 @@
+package stuff
+
+import "time"
+
+// Dates are turned into a count of days since
+// 1 January 1970 to do arithmetic on them. This is
+// the days from civil algorithm, which works for any
+// year in the proleptic Gregorian calendar
+func (d Date) serial() int {
+	y, m := d.year, d.month
+	if m <= 2 {
+		y--
+	}
+	era := floorDiv(y, 400)
+	yearOfEra := y - era*400
+	// Count months from March so February is last
+	mp := (m + 9) % 12
+	dayOfYear := (153*mp+2)/5 + d.day - 1
+	dayOfEra := yearOfEra*365 + yearOfEra/4 - yearOfEra/100 + dayOfYear
+	return era*146097 + dayOfEra - 719468
+}
+
+// Turn a count of days since 1 January 1970 back into
+// a year, month and day
+func fromSerial(z int) (y, m, d int) {
+	z += 719468
+	era := floorDiv(z, 146097)
+	dayOfEra := z - era*146097
+	yearOfEra := (dayOfEra - dayOfEra/1460 + dayOfEra/36524 - dayOfEra/146096) / 365
+	dayOfYear := dayOfEra - (365*yearOfEra + yearOfEra/4 - yearOfEra/100)
+	mp := (5*dayOfYear + 2) / 153
+	d = dayOfYear - (153*mp+2)/5 + 1
+	m = (mp+2)%12 + 1
+	y = yearOfEra + era*400
+	if m <= 2 {
+		y++
+	}
+	return y, m, d
+}
+
+// Division that rounds down for negative numbers too
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// Return the date n days later, or earlier if n is
+// negative. It fails if that leaves the allowed years
+func (d Date) AddDays(n int) (Date, error) {
+	return d.withDate(fromSerial(d.serial() + n))
+}
+
+// Return the date n months later. If the day doesn't
+// exist in that month the last day is used, so
+// 31 January plus a month is 28 or 29 February
+func (d Date) AddMonths(n int) (Date, error) {
+	months := d.year*12 + d.month - 1 + n
+	y, m := floorDiv(months, 12), months-floorDiv(months, 12)*12+1
+	day := d.day
+	if day > daysIn(y, m) {
+		day = daysIn(y, m)
+	}
+	return d.withDate(y, m, day)
+}
+
+// Return the date n years later. 29 February becomes
+// 28 February in years that aren't leap years
+func (d Date) AddYears(n int) (Date, error) {
+	return d.AddMonths(n * 12)
+}
+
+// Return the number of days from d to other. It is
+// negative if other is earlier
+func (d Date) DaysBetween(other Date) int {
+	return other.serial() - d.serial()
+}
+
+// Compare returns -1 if d is before other, 1 if it is
+// after and 0 if they are the same day
+func (d Date) Compare(other Date) int {
+	switch {
+	case d.serial() < other.serial():
+		return -1
+	case d.serial() > other.serial():
+		return 1
+	}
+	return 0
+}
+
+// Check if d is an earlier day than other
+func (d Date) Before(other Date) bool {
+	return d.Compare(other) < 0
+}
+
+// Check if d is a later day than other
+func (d Date) After(other Date) bool {
+	return d.Compare(other) > 0
+}
+
+// Check if d and other are the same day, even if
+// they have different policies
+func (d Date) Equal(other Date) bool {
+	return d.year == other.year && d.month == other.month && d.day == other.day
+}
+
+// Return the day of the week. 1 January 1970 was
+// a Thursday
+func (d Date) Weekday() time.Weekday {
+	return time.Weekday((d.serial()%7 + 7 + int(time.Thursday)) % 7)
+}
+
+// Return the day of the year from 1 to 366
+func (d Date) DayOfYear() int {
+	return d.serial() - Date{day: 1, month: 1, year: d.year}.serial() + 1
+}
+
+// Return the ISO 8601 year and week number. Weeks
+// start on Monday and week 1 holds the year's first
+// Thursday, so early January can be in the last week
+// of the year before
+func (d Date) ISOWeek() (year, week int) {
+	// Monday is 0 and Sunday is 6
+	fromMonday := (int(d.Weekday()) + 6) % 7
+	thursday := d.serial() - fromMonday + 3
+	year, _, _ = fromSerial(thursday)
+	jan1 := Date{day: 1, month: 1, year: year}.serial()
+	return year, (thursday-jan1)/7 + 1
+}
@@ -0,0 +1,120 @@
+package stuff
+
+import (
+	"testing"
+	"time"
+)
+
+// Walk every day of three centuries checking against
+// time.Time
+func TestArithmeticMatchesTime(t *testing.T) {
+	policy := Policy{Max: Date{day: 31, month: 12, year: 2100}, AllowFuture: true}
+	date, err := policy.NewDate(1800, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := date
+	tm := time.Date(1800, 1, 1, 0, 0, 0, 0, time.UTC)
+	for n := 0; tm.Year() <= 2100; n++ {
+		if date.Year() != tm.Year() || date.Month() != int(tm.Month()) || date.Day() != tm.Day() {
+			t.Fatalf("day %d: got %v, want %v", n, date, tm.Format("2006-01-02"))
+		}
+		if date.Weekday() != tm.Weekday() {
+			t.Errorf("%v: Weekday %v, want %v", date, date.Weekday(), tm.Weekday())
+		}
+		if date.DayOfYear() != tm.YearDay() {
+			t.Errorf("%v: DayOfYear %d, want %d", date, date.DayOfYear(), tm.YearDay())
+		}
+		year, week := date.ISOWeek()
+		wantYear, wantWeek := tm.ISOWeek()
+		if year != wantYear || week != wantWeek {
+			t.Errorf("%v: ISOWeek %d-%d, want %d-%d", date, year, week, wantYear, wantWeek)
+		}
+		if start.DaysBetween(date) != n {
+			t.Fatalf("%v: DaysBetween %d, want %d", date, start.DaysBetween(date), n)
+		}
+		tm = tm.AddDate(0, 0, 1)
+		next, err := date.AddDays(1)
+		if err != nil {
+			// Only the day after Max can be out of range
+			if tm.Year() <= 2100 {
+				t.Fatalf("%v: AddDays: %v", date, err)
+			}
+			break
+		}
+		date = next
+	}
+}
+
+func TestAddDaysJumps(t *testing.T) {
+	date, _ := NewDate(1900, 2, 28)
+	for _, n := range []int{1, -1, 365, 36524, -9000} {
+		got, err := date.AddDays(n)
+		want := time.Date(1900, 2, 28+n, 0, 0, 0, 0, time.UTC)
+		if err != nil {
+			t.Errorf("AddDays(%d): %v", n, err)
+		} else if got.String() != want.Format("2006-01-02") {
+			t.Errorf("AddDays(%d) = %v, want %v", n, got, want.Format("2006-01-02"))
+		}
+	}
+	if _, err := date.AddDays(-10000); err == nil {
+		t.Error("AddDays went before the earliest year")
+	}
+}
+
+func TestAddMonthsClamps(t *testing.T) {
+	tests := []struct {
+		from   string
+		months int
+		years  int
+		want   string
+	}{
+		{"2024-01-31", 1, 0, "2024-02-29"},
+		{"2023-01-31", 1, 0, "2023-02-28"},
+		{"2023-03-31", -1, 0, "2023-02-28"},
+		{"2023-05-31", 1, 0, "2023-06-30"},
+		{"2023-11-15", 3, 0, "2024-02-15"},
+		{"2024-02-15", -14, 0, "2022-12-15"},
+		{"2000-02-29", 0, 1, "2001-02-28"},
+		{"1896-02-29", 0, 4, "1900-02-28"},
+		{"1896-02-29", 0, 8, "1904-02-29"},
+	}
+	for _, test := range tests {
+		from, err := Parse(test.from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := from.AddMonths(test.months)
+		if test.years != 0 {
+			got, err = from.AddYears(test.years)
+		}
+		if err != nil {
+			t.Errorf("%s %+d months %+d years: %v", test.from, test.months, test.years, err)
+		} else if got.String() != test.want {
+			t.Errorf("%s %+d months %+d years = %v, want %s",
+				test.from, test.months, test.years, got, test.want)
+		}
+	}
+	date, _ := NewDate(1875, 3, 1)
+	if _, err := date.AddMonths(-3); err == nil {
+		t.Error("AddMonths went before the earliest year")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a, _ := NewDate(1999, 12, 31)
+	b, _ := NewDate(2000, 1, 1)
+	if !a.Before(b) || a.After(b) || a.Compare(b) != -1 {
+		t.Error("1999-12-31 should be before 2000-01-01")
+	}
+	if !b.After(a) || b.Compare(a) != 1 {
+		t.Error("2000-01-01 should be after 1999-12-31")
+	}
+	c, _ := Parse("2000-01-01")
+	if !b.Equal(c) || b.Compare(c) != 0 || b.Before(c) || b.After(c) {
+		t.Error("2000-01-01 should equal itself")
+	}
+	if a.DaysBetween(b) != 1 || b.DaysBetween(a) != -1 {
+		t.Errorf("DaysBetween = %d and %d", a.DaysBetween(b), b.DaysBetween(a))
+	}
+}
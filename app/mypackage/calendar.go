@@ -0,0 +1,226 @@
+package stuff
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// CalendarFormat is how a calendar is written out
+type CalendarFormat int
+
+const (
+	// Text like the cal command prints. Highlighted
+	// days have a * after them
+	PlainText CalendarFormat = iota
+	// Text with highlighted days in reverse video for
+	// terminals
+	ANSIText
+	// An HTML table per month. Highlighted days have the
+	// highlight class
+	HTMLTable
+)
+
+// Calendar draws months and years as a grid of days.
+// The zero value is a plain text calendar with weeks
+// starting on Sunday, like cal
+type Calendar struct {
+	// The day weeks start on, usually Sunday or Monday
+	FirstDay time.Weekday
+	// Show ISO 8601 week numbers down the left
+	WeekNumbers bool
+	// Days to pick out, like holidays or due dates
+	Highlight []Date
+	Format    CalendarFormat
+}
+
+// Reverse video and back to normal for ANSIText
+const (
+	ansiReverse = "\x1b[7m"
+	ansiReset   = "\x1b[0m"
+)
+
+// Return the month a date is in as a grid
+func (c Calendar) Month(d Date) string {
+	if c.Format == HTMLTable {
+		return c.monthHTML(d.year, d.month)
+	}
+	return joinLines(c.monthLines(d.year, d.month, 0))
+}
+
+// Return the year a date is in as a grid of months,
+// three across for text
+func (c Calendar) Year(d Date) string {
+	if c.Format == HTMLTable {
+		var out strings.Builder
+		fmt.Fprintf(&out, "<div class=\"calendar-year\">\n<h2>%d</h2>\n", d.year)
+		for m := 1; m <= 12; m++ {
+			out.WriteString(c.monthHTML(d.year, m))
+		}
+		out.WriteString("</div>\n")
+		return out.String()
+	}
+
+	var lines []string
+	lines = append(lines, center(fmt.Sprint(d.year), 3*c.width()), "")
+	for first := 1; first <= 12; first += 3 {
+		// Every month gets six weeks so they line up
+		var months [][]string
+		for m := first; m < first+3; m++ {
+			months = append(months, c.monthLines(d.year, m, 6))
+		}
+		for i := range months[0] {
+			lines = append(lines, months[0][i]+months[1][i]+months[2][i])
+		}
+		lines = append(lines, "")
+	}
+	return joinLines(lines[:len(lines)-1])
+}
+
+// How wide each line of a text month is. Every cell
+// is three characters including the space after it
+func (c Calendar) width() int {
+	if c.WeekNumbers {
+		return 8 * 3
+	}
+	return 7 * 3
+}
+
+// Return the weeks of a month. Each week is the serial
+// day it starts on, see Date.serial
+func (c Calendar) weeks(y, m int) []int {
+	first := Date{day: 1, month: m, year: y}
+	start := first.serial() - (int(first.Weekday())-int(c.FirstDay)+7)%7
+	last := Date{day: daysIn(y, m), month: m, year: y}.serial()
+	var weeks []int
+	for week := start; week <= last; week += 7 {
+		weeks = append(weeks, week)
+	}
+	return weeks
+}
+
+// Return the ISO week number of the week starting on a
+// serial day, going by its Monday
+func (c Calendar) weekNumber(start int) int {
+	toMonday := (int(time.Monday) - int(c.FirstDay) + 7) % 7
+	_, week := dateFromSerial(start + toMonday).ISOWeek()
+	return week
+}
+
+// Return the two letter names of the days in order
+func (c Calendar) dayNames() []string {
+	var names []string
+	for i := 0; i < 7; i++ {
+		names = append(names, time.Weekday((int(c.FirstDay) + i) % 7).String()[:2])
+	}
+	return names
+}
+
+// Check if a day is to be picked out
+func (c Calendar) highlighted(d Date) bool {
+	for _, h := range c.Highlight {
+		if h.Equal(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the lines of a text month, padded with blank
+// weeks up to rows. Every line is the same width
+func (c Calendar) monthLines(y, m, rows int) []string {
+	width := c.width()
+	header := strings.Join(c.dayNames(), " ") + " "
+	if c.WeekNumbers {
+		header = "Wk " + header
+	}
+	lines := []string{
+		center(fmt.Sprintf("%s %d", monthName(m), y), width),
+		header,
+	}
+	for _, week := range c.weeks(y, m) {
+		var line strings.Builder
+		if c.WeekNumbers {
+			fmt.Fprintf(&line, "%2d ", c.weekNumber(week))
+		}
+		for i := 0; i < 7; i++ {
+			day := dateFromSerial(week + i)
+			switch {
+			case day.month != m:
+				line.WriteString("   ")
+			case !c.highlighted(day):
+				fmt.Fprintf(&line, "%2d ", day.day)
+			case c.Format == ANSIText:
+				fmt.Fprintf(&line, "%s%2d%s ", ansiReverse, day.day, ansiReset)
+			default:
+				fmt.Fprintf(&line, "%2d*", day.day)
+			}
+		}
+		lines = append(lines, line.String())
+	}
+	for len(lines) < rows+2 {
+		lines = append(lines, strings.Repeat(" ", width))
+	}
+	return lines
+}
+
+// Return a month as an HTML table. Days carry their
+// date in a time element for scripts to read
+func (c Calendar) monthHTML(y, m int) string {
+	var out strings.Builder
+	out.WriteString("<table class=\"calendar\">\n")
+	fmt.Fprintf(&out, "<caption>%s %d</caption>\n", html.EscapeString(monthName(m)), y)
+	out.WriteString("<thead><tr>")
+	if c.WeekNumbers {
+		out.WriteString("<th>Wk</th>")
+	}
+	for _, name := range c.dayNames() {
+		fmt.Fprintf(&out, "<th>%s</th>", name)
+	}
+	out.WriteString("</tr></thead>\n<tbody>\n")
+	for _, week := range c.weeks(y, m) {
+		out.WriteString("<tr>")
+		if c.WeekNumbers {
+			fmt.Fprintf(&out, "<td class=\"week\">%d</td>", c.weekNumber(week))
+		}
+		for i := 0; i < 7; i++ {
+			day := dateFromSerial(week + i)
+			switch {
+			case day.month != m:
+				out.WriteString("<td></td>")
+			case c.highlighted(day):
+				fmt.Fprintf(&out, "<td class=\"highlight\"><time datetime=\"%v\">%d</time></td>", day, day.day)
+			default:
+				fmt.Fprintf(&out, "<td><time datetime=\"%v\">%d</time></td>", day, day.day)
+			}
+		}
+		out.WriteString("</tr>\n")
+	}
+	out.WriteString("</tbody>\n</table>\n")
+	return out.String()
+}
+
+// Center text in a line. The last character of a
+// line is the space after a cell, so it isn't counted
+func center(text string, width int) string {
+	left := (width - 1 - len(text)) / 2
+	if left < 0 {
+		left = 0
+	}
+	line := strings.Repeat(" ", left) + text
+	if len(line) < width {
+		line += strings.Repeat(" ", width-len(line))
+	}
+	return line
+}
+
+// Join lines without the spaces on the end of them
+func joinLines(lines []string) string {
+	var out strings.Builder
+	for _, line := range lines {
+		out.WriteString(strings.TrimRight(line, " "))
+		out.WriteString("\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,83 @@
+package stuff
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarMonth(t *testing.T) {
+	date := mustParse(t, "1974-12-21")[0]
+	// What cal 12 1974 prints
+	want := `   December 1974
+Su Mo Tu We Th Fr Sa
+ 1  2  3  4  5  6  7
+ 8  9 10 11 12 13 14
+15 16 17 18 19 20 21
+22 23 24 25 26 27 28
+29 30 31
+`
+	if got := (Calendar{}).Month(date); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	iso := Calendar{FirstDay: time.Monday, WeekNumbers: true, Highlight: []Date{date}}
+	want = `     December 1974
+Wk Mo Tu We Th Fr Sa Su
+48                    1
+49  2  3  4  5  6  7  8
+50  9 10 11 12 13 14 15
+51 16 17 18 19 20 21*22
+52 23 24 25 26 27 28 29
+ 1 30 31
+`
+	if got := iso.Month(date); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	ansi := Calendar{Highlight: []Date{date}, Format: ANSIText}.Month(date)
+	if !strings.Contains(ansi, "20 \x1b[7m21\x1b[0m\n") {
+		t.Errorf("21 isn't in reverse video:\n%q", ansi)
+	}
+}
+
+func TestCalendarYear(t *testing.T) {
+	year := Calendar{FirstDay: time.Monday}.Year(mustParse(t, "2024-06-01")[0])
+	lines := strings.Split(year, "\n")
+	// The year, a gap, then four rows of months with 8
+	// lines each and gaps between them
+	if len(lines) != 2+4*8+3+1 {
+		t.Fatalf("got %d lines:\n%s", len(lines), year)
+	}
+	if strings.TrimSpace(lines[0]) != "2024" {
+		t.Errorf("first line = %q", lines[0])
+	}
+	if !strings.Contains(lines[2], "January 2024") || !strings.Contains(lines[2], "March 2024") {
+		t.Errorf("first row of months = %q", lines[2])
+	}
+	// 1 January 2024 was a Monday and 1 March a Friday
+	if lines[4] != " 1  2  3  4  5  6  7           1  2  3  4              1  2  3" {
+		t.Errorf("first week = %q", lines[4])
+	}
+}
+
+func TestCalendarHTML(t *testing.T) {
+	date := mustParse(t, "2024-02-29")[0]
+	page := Calendar{WeekNumbers: true, Highlight: []Date{date}, Format: HTMLTable}.Month(date)
+	for _, want := range []string{
+		"<caption>February 2024</caption>",
+		"<th>Wk</th><th>Su</th>",
+		`<td class="highlight"><time datetime="2024-02-29">29</time></td>`,
+		// The week of Sunday 28 January goes by Monday 29th
+		`<tr><td class="week">5</td><td></td><td></td><td></td><td></td>` +
+			`<td><time datetime="2024-02-01">1</time></td>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("missing %s in\n%s", want, page)
+		}
+	}
+	year := Calendar{Format: HTMLTable}.Year(date)
+	if strings.Count(year, "<table") != 12 {
+		t.Errorf("got %d months", strings.Count(year, "<table"))
+	}
+}
@@ -0,0 +1,165 @@
+package stuff
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DateCSV reads and writes CSV tables with some date
+// columns. Cells in the date columns are parsed and
+// checked, the other cells are passed through as they
+// are. Empty date cells are zero dates
+type DateCSV struct {
+	// The layout of the dates, ISOLayout if empty
+	Layout string
+	// The first row holds the column names
+	Header bool
+	// Positions of the date columns, counting from 0
+	// like a record's index. No columns means no dates
+	Columns []int
+	// Which dates are allowed, the default if nil
+	Policy *Policy
+}
+
+// CSVRow is one row of a table. Fields holds every
+// cell as it was written and Dates the parsed dates at
+// the same positions, zero outside the date columns
+type CSVRow struct {
+	Fields []string
+	Dates  []Date
+}
+
+// CSVError is a cell that isn't a valid date. Row is
+// the line in the file the cell starts on, so a quoted
+// cell over several lines moves the rows after it down.
+// Both count from 1
+type CSVError struct {
+	Row    int
+	Column int
+	Err    error
+}
+
+func (e *CSVError) Error() string {
+	return fmt.Sprintf("row %d, column %d: %v", e.Row, e.Column, e.Err)
+}
+
+func (e *CSVError) Unwrap() error {
+	return e.Err
+}
+
+// CSVErrors is every bad cell in a table, so they can
+// all be fixed at once
+type CSVErrors []*CSVError
+
+func (errs CSVErrors) Error() string {
+	var lines []string
+	for _, err := range errs {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c DateCSV) layout() string {
+	if c.Layout == "" {
+		return ISOLayout
+	}
+	return c.Layout
+}
+
+// Check if a column holds dates
+func (c DateCSV) isDateColumn(j int) bool {
+	for _, column := range c.Columns {
+		if column == j {
+			return true
+		}
+	}
+	return false
+}
+
+// Read a table. The rows are returned even if some
+// date cells were bad, with zero dates in those cells
+// and a CSVErrors listing them
+func (c DateCSV) Read(r io.Reader) (header []string, rows []CSVRow, err error) {
+	reader := csv.NewReader(r)
+	if c.Header {
+		header, err = reader.Read()
+		if err == io.EOF {
+			return nil, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Dates get the reader's policy, or the default
+	parse := ParseLayout
+	if c.Policy != nil {
+		parse = c.Policy.ParseLayout
+	}
+	var errs CSVErrors
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		row := CSVRow{Fields: record, Dates: make([]Date, len(record))}
+		for j, cell := range record {
+			if !c.isDateColumn(j) || strings.TrimSpace(cell) == "" {
+				continue
+			}
+			date, err := parse(c.layout(), strings.TrimSpace(cell))
+			if err != nil {
+				line, _ := reader.FieldPos(j)
+				errs = append(errs, &CSVError{Row: line, Column: j + 1, Err: err})
+				continue
+			}
+			row.Dates[j] = date
+		}
+		rows = append(rows, row)
+	}
+	if errs != nil {
+		return header, rows, errs
+	}
+	return header, rows, nil
+}
+
+// Write a table. Date columns are written from Dates
+// in the layout and the other cells from Fields. A nil
+// header isn't written
+func (c DateCSV) Write(w io.Writer, header []string, rows []CSVRow) error {
+	writer := csv.NewWriter(w)
+	if header != nil {
+		err := writer.Write(header)
+		if err != nil {
+			return err
+		}
+	}
+	for _, row := range rows {
+		size := len(row.Fields)
+		if len(row.Dates) > size {
+			size = len(row.Dates)
+		}
+		record := make([]string, size)
+		copy(record, row.Fields)
+		for j := range record {
+			if !c.isDateColumn(j) {
+				continue
+			}
+			record[j] = ""
+			if j < len(row.Dates) && !row.Dates[j].IsZero() {
+				record[j] = row.Dates[j].Format(c.layout())
+			}
+		}
+		err := writer.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
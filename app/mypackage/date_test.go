@@ -0,0 +1,189 @@
+package stuff
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestNewDateValidatesWholeDate(t *testing.T) {
+	valid := [][3]int{
+		{2000, 2, 29}, // divisible by 400
+		{2024, 2, 29},
+		{1974, 12, 21},
+		{2023, 4, 30},
+		{1875, 1, 1},
+	}
+	for _, v := range valid {
+		if _, err := NewDate(v[0], v[1], v[2]); err != nil {
+			t.Errorf("%v: %v", v, err)
+		}
+	}
+
+	invalid := []struct {
+		y, m, d int
+		field   DateField
+	}{
+		{2023, 2, 29, FieldDay},
+		{1900, 2, 29, FieldDay}, // century not divisible by 400
+		{2023, 2, 31, FieldDay},
+		{2023, 4, 31, FieldDay},
+		{2023, 1, 0, FieldDay},
+		{2023, 13, 1, FieldMonth},
+		{2023, 0, 1, FieldMonth},
+		{1874, 12, 31, FieldYear},
+	}
+	for _, test := range invalid {
+		_, err := NewDate(test.y, test.m, test.d)
+		var dateErr *DateError
+		if !errors.As(err, &dateErr) {
+			t.Errorf("%d-%d-%d: got %v, want a DateError", test.y, test.m, test.d, err)
+			continue
+		}
+		if dateErr.Field != test.field {
+			t.Errorf("%d-%d-%d: blamed the %s, want the %s",
+				test.y, test.m, test.d, dateErr.Field, test.field)
+		}
+	}
+}
+
+func TestSetIsAtomic(t *testing.T) {
+	date, err := NewDate(2024, 1, 31)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := date.Set(2024, 2, 31); err == nil {
+		t.Fatal("accepted 31 February")
+	}
+	if date.Year() != 2024 || date.Month() != 1 || date.Day() != 31 {
+		t.Errorf("failed Set changed the date to %d-%d-%d", date.Year(), date.Month(), date.Day())
+	}
+	if err := date.Set(2024, 2, 29); err != nil {
+		t.Fatal(err)
+	}
+	if date.Month() != 2 || date.Day() != 29 {
+		t.Errorf("Set gave %d-%d", date.Month(), date.Day())
+	}
+}
+
+func TestFormatAndParse(t *testing.T) {
+	date, err := NewDate(1974, 12, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct{ layout, want string }{
+		{ISOLayout, "1974-12-05"},
+		{"MM/DD/YYYY", "12/05/1974"},
+		{"M/D/YY", "12/5/74"},
+		{"D MMM YYYY", "5 Dec 1974"},
+		{"MMMM D, YYYY", "December 5, 1974"},
+	}
+	for _, test := range tests {
+		got := date.Format(test.layout)
+		if got != test.want {
+			t.Errorf("Format(%q) = %q, want %q", test.layout, got, test.want)
+		}
+		parsed, err := ParseLayout(test.layout, got)
+		if err != nil {
+			t.Errorf("ParseLayout(%q, %q): %v", test.layout, got, err)
+		} else if parsed != date {
+			t.Errorf("ParseLayout(%q, %q) = %v", test.layout, got, parsed)
+		}
+	}
+	if date.String() != "1974-12-05" {
+		t.Errorf("String() = %q", date.String())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	bad := []string{"1974-12", "1974-12-05x", "74-12-05", "1974/12/05", ""}
+	for _, s := range bad {
+		var parseErr *ParseError
+		if _, err := Parse(s); !errors.As(err, &parseErr) {
+			t.Errorf("Parse(%q): got %v, want a ParseError", s, err)
+		}
+	}
+	// Well formed but not a real day
+	var dateErr *DateError
+	if _, err := Parse("2023-02-29"); !errors.As(err, &dateErr) {
+		t.Errorf("Parse(2023-02-29): got %v, want a DateError", err)
+	}
+}
+
+func TestMarshaling(t *testing.T) {
+	type event struct {
+		Name string `json:"name"`
+		On   Date   `json:"on"`
+		Was  *Date  `json:"was"`
+	}
+	on, _ := NewDate(2024, 2, 29)
+	data, err := json.Marshal(event{Name: "leap", On: on})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"name":"leap","on":"2024-02-29","was":null}`
+	if string(data) != want {
+		t.Errorf("Marshal = %s, want %s", data, want)
+	}
+	var back event
+	err = json.Unmarshal(data, &back)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.On != on || back.Was != nil {
+		t.Errorf("Unmarshal = %+v", back)
+	}
+	if err := json.Unmarshal([]byte(`{"on":"2023-02-29"}`), &back); err == nil {
+		t.Error("unmarshaled 29 February 2023")
+	}
+
+	// Text marshaling makes Dates usable as map keys
+	byDay := map[Date]string{on: "leap day"}
+	data, err = json.Marshal(byDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"2024-02-29":"leap day"}` {
+		t.Errorf("Marshal map = %s", data)
+	}
+	var zero Date
+	if text, _ := zero.MarshalText(); len(text) != 0 {
+		t.Errorf("zero date marshaled to %q", text)
+	}
+}
+
+// Decoding checks dates against the package default,
+// so a program that stores due dates can allow the
+// future
+func TestUnmarshalUsesDefaultPolicy(t *testing.T) {
+	scheduling := Scheduling()
+	scheduling.Clock = fixedClock
+	due, err := scheduling.NewDate(2030, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(map[string]Date{"due": due})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	birthdate := Birthdate()
+	birthdate.Clock = fixedClock
+	testDefaultPolicy(t, birthdate)
+	var back map[string]Date
+	if err := json.Unmarshal(data, &back); err == nil {
+		t.Error("decoded a future date with Birthdate as the default")
+	}
+	testDefaultPolicy(t, scheduling)
+	back = nil
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !back["due"].Equal(due) {
+		t.Errorf("round trip gave %v, want %v", back["due"], due)
+	}
+	var text Date
+	if err := text.UnmarshalText([]byte("2030-01-01")); err != nil || !text.Equal(due) {
+		t.Errorf("UnmarshalText = %v, %v", text, err)
+	}
+}
@@ -0,0 +1,100 @@
+package stuff
+
+import "fmt"
+
+// DateRange is every day from Start to End, including
+// both of them
+type DateRange struct {
+	Start Date
+	End   Date
+}
+
+// Create a range, making sure it doesn't end before
+// it starts
+func NewDateRange(start, end Date) (DateRange, error) {
+	if end.Before(start) {
+		return DateRange{}, fmt.Errorf("range ends on %v before it starts on %v", end, start)
+	}
+	return DateRange{Start: start, End: end}, nil
+}
+
+// Return the range like 2024-01-01/2024-01-31, which is
+// how ISO 8601 writes intervals
+func (r DateRange) String() string {
+	return r.Start.String() + "/" + r.End.String()
+}
+
+// Return how many days are in the range
+func (r DateRange) Days() int {
+	return r.Start.DaysBetween(r.End) + 1
+}
+
+// Check if a day is in the range
+func (r DateRange) Contains(d Date) bool {
+	return !d.Before(r.Start) && !d.After(r.End)
+}
+
+// Check if two ranges share at least one day
+func (r DateRange) Overlaps(other DateRange) bool {
+	return !r.End.Before(other.Start) && !other.End.Before(r.Start)
+}
+
+// Return the days two ranges share. It returns false
+// if they don't overlap
+func (r DateRange) Intersect(other DateRange) (DateRange, bool) {
+	if !r.Overlaps(other) {
+		return DateRange{}, false
+	}
+	result := r
+	if other.Start.After(result.Start) {
+		result.Start = other.Start
+	}
+	if other.End.Before(result.End) {
+		result.End = other.End
+	}
+	return result, true
+}
+
+// Split the range into one range per calendar month.
+// The first and last can be part of a month
+func (r DateRange) SplitByMonth() []DateRange {
+	var months []DateRange
+	for start := r.Start; !start.After(r.End); {
+		end := start
+		end.day = daysIn(start.year, start.month)
+		if end.After(r.End) {
+			end = r.End
+		}
+		months = append(months, DateRange{Start: start, End: end})
+		start = end.next()
+	}
+	return months
+}
+
+// Call f for each day in order until it returns false
+func (r DateRange) Each(f func(Date) bool) {
+	for d := r.Start; !d.After(r.End); d = d.next() {
+		if !f(d) {
+			return
+		}
+	}
+}
+
+// Return every day in the range
+func (r DateRange) Dates() []Date {
+	dates := make([]Date, 0, r.Days())
+	r.Each(func(d Date) bool {
+		dates = append(dates, d)
+		return true
+	})
+	return dates
+}
+
+// Return the next day with the same policy. It isn't
+// checked, so only use it for days that are inside
+// a range of valid dates
+func (d Date) next() Date {
+	next := dateFromSerial(d.serial() + 1)
+	next.policy = d.policy
+	return next
+}
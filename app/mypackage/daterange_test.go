@@ -0,0 +1,104 @@
+package stuff
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Read ISO dates for tests, failing the test on errors
+func mustParse(t *testing.T, days ...string) []Date {
+	t.Helper()
+	var dates []Date
+	for _, day := range days {
+		date, err := Scheduling().Parse(day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dates = append(dates, date)
+	}
+	return dates
+}
+
+func mustRange(t *testing.T, start, end string) DateRange {
+	t.Helper()
+	days := mustParse(t, start, end)
+	r, err := NewDateRange(days[0], days[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// Write dates as ISO strings so failures are readable
+func isoStrings(dates []Date) []string {
+	var out []string
+	for _, date := range dates {
+		out = append(out, date.String())
+	}
+	return out
+}
+
+func TestDateRange(t *testing.T) {
+	r := mustRange(t, "2024-01-30", "2024-03-02")
+	if r.Days() != 33 {
+		t.Errorf("Days = %d, want 33", r.Days())
+	}
+	for day, want := range map[string]bool{
+		"2024-01-29": false, "2024-01-30": true, "2024-02-29": true,
+		"2024-03-02": true, "2024-03-03": false,
+	} {
+		if got := r.Contains(mustParse(t, day)[0]); got != want {
+			t.Errorf("Contains(%s) = %v", day, got)
+		}
+	}
+
+	var months []string
+	for _, month := range r.SplitByMonth() {
+		months = append(months, month.String())
+	}
+	want := []string{"2024-01-30/2024-01-31", "2024-02-01/2024-02-29", "2024-03-01/2024-03-02"}
+	if !reflect.DeepEqual(months, want) {
+		t.Errorf("SplitByMonth = %v, want %v", months, want)
+	}
+
+	dates := r.Dates()
+	if len(dates) != r.Days() || !dates[0].Equal(r.Start) || !dates[len(dates)-1].Equal(r.End) {
+		t.Errorf("Dates = %v", isoStrings(dates))
+	}
+	seen := 0
+	r.Each(func(Date) bool {
+		seen++
+		return seen < 3
+	})
+	if seen != 3 {
+		t.Errorf("Each didn't stop, saw %d days", seen)
+	}
+
+	start, end := r.Start, r.End
+	if _, err := NewDateRange(end, start); err == nil {
+		t.Error("NewDateRange allowed a backwards range")
+	}
+}
+
+func TestRangeOverlaps(t *testing.T) {
+	a := mustRange(t, "2024-01-01", "2024-01-31")
+	tests := []struct {
+		b, want string // want is empty if they don't overlap
+	}{
+		{"2024-01-31/2024-02-10", "2024-01-31/2024-01-31"},
+		{"2023-12-01/2024-01-05", "2024-01-01/2024-01-05"},
+		{"2024-01-10/2024-01-20", "2024-01-10/2024-01-20"},
+		{"2024-02-01/2024-02-10", ""},
+		{"2023-12-01/2023-12-31", ""},
+	}
+	for _, test := range tests {
+		b := mustRange(t, test.b[:10], test.b[11:])
+		got, ok := a.Intersect(b)
+		if a.Overlaps(b) != (test.want != "") || b.Overlaps(a) != (test.want != "") {
+			t.Errorf("Overlaps(%s) = %v", test.b, a.Overlaps(b))
+		}
+		if ok != (test.want != "") || (ok && got.String() != test.want) {
+			t.Errorf("Intersect(%s) = %v %v, want %q", test.b, got, ok, test.want)
+		}
+	}
+}
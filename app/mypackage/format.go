@@ -0,0 +1,278 @@
+package stuff
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ISO 8601 is the layout String and Parse use
+const ISOLayout = "YYYY-MM-DD"
+
+// Layout tokens, longest first so MMMM is tried
+// before MMM, MM and M
+var layoutTokens = []string{"YYYY", "MMMM", "MMM", "MM", "DD", "YY", "M", "D"}
+
+// ParseError says which text couldn't be read with
+// which layout
+type ParseError struct {
+	Layout string
+	Value  string
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("cannot parse %q as %q: %s", e.Value, e.Layout, e.Reason)
+}
+
+// Check if the date was never set
+func (d Date) IsZero() bool {
+	return d.Equal(Date{})
+}
+
+// Return the date in ISO 8601 like 1974-12-21
+func (d Date) String() string {
+	return d.Format(ISOLayout)
+}
+
+// Format the date with a layout such as MM/DD/YYYY.
+// The tokens are YYYY and YY for the year, MMMM for
+// December, MMM for Dec, MM for 12 or 01, M for 1,
+// DD for 05 and D for 5. Everything else is copied
+func (d Date) Format(layout string) string {
+	var out strings.Builder
+	for layout != "" {
+		token := nextToken(layout)
+		switch token {
+		case "YYYY":
+			fmt.Fprintf(&out, "%04d", d.year)
+		case "YY":
+			fmt.Fprintf(&out, "%02d", d.year%100)
+		case "MMMM":
+			out.WriteString(monthName(d.month))
+		case "MMM":
+			out.WriteString(shortMonthName(d.month))
+		case "MM":
+			fmt.Fprintf(&out, "%02d", d.month)
+		case "M":
+			out.WriteString(strconv.Itoa(d.month))
+		case "DD":
+			fmt.Fprintf(&out, "%02d", d.day)
+		case "D":
+			out.WriteString(strconv.Itoa(d.day))
+		default:
+			out.WriteString(token)
+		}
+		layout = layout[len(token):]
+	}
+	return out.String()
+}
+
+// Return the token at the start of a layout, or its
+// first character if it doesn't start with one
+func nextToken(layout string) string {
+	for _, token := range layoutTokens {
+		if strings.HasPrefix(layout, token) {
+			return token
+		}
+	}
+	return layout[:1]
+}
+
+// Names of months, with a blank for the zero month
+func monthName(m int) string {
+	if m < 1 || m > 12 {
+		return ""
+	}
+	return time.Month(m).String()
+}
+func shortMonthName(m int) string {
+	return truncate(monthName(m), 3)
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// Read an ISO 8601 date like 1974-12-21
+func Parse(s string) (Date, error) {
+	return ParseLayout(ISOLayout, s)
+}
+
+// Read a date written with a layout, see Format for
+// the tokens. The date is checked like NewDate does
+func ParseLayout(layout, s string) (Date, error) {
+	return Date{}.parse(layout, s)
+}
+
+// Read an ISO 8601 date and check it against this
+// policy
+func (p Policy) Parse(s string) (Date, error) {
+	return p.ParseLayout(ISOLayout, s)
+}
+
+// Read a date with a layout and check it against this
+// policy, keeping the policy like Policy.NewDate
+func (p Policy) ParseLayout(layout, s string) (Date, error) {
+	return parseChecked(p, p.kept(), layout, s)
+}
+
+// Read a date with a layout, giving it d's policy
+func (d Date) parse(layout, s string) (Date, error) {
+	return parseChecked(d.rules(), d.policy, layout, s)
+}
+
+// Read a date with a layout, check it against p by
+// p's clock and give it the rules kept
+func parseChecked(p Policy, kept dateRules, layout, s string) (Date, error) {
+	y, m, day, err := parseFields(layout, s, p.now())
+	if err != nil {
+		return Date{}, err
+	}
+	err = p.validate(y, m, day)
+	if err != nil {
+		return Date{}, fmt.Errorf("parsing %q: %w", s, err)
+	}
+	return Date{day: day, month: m, year: y, policy: kept}, nil
+}
+
+// Read the year, month and day from text written with
+// a layout without checking they make a real date.
+// Two digit years are read relative to now
+func parseFields(layout, s string, now time.Time) (y, m, d int, err error) {
+	fail := func(reason string) (int, int, int, error) {
+		return 0, 0, 0, &ParseError{Layout: layout, Value: s, Reason: reason}
+	}
+	text := s
+	for lay := layout; lay != ""; {
+		token := nextToken(lay)
+		lay = lay[len(token):]
+		var n int
+		switch token {
+		case "YYYY":
+			n, text, err = readDigits(text, 4, 4)
+			y = n
+		case "YY":
+			n, text, err = readDigits(text, 2, 2)
+			y = expandYear(n, now)
+		case "MM", "DD":
+			n, text, err = readDigits(text, 2, 2)
+		case "M", "D":
+			n, text, err = readDigits(text, 1, 2)
+		case "MMMM", "MMM":
+			n, text, err = readMonthName(text, token == "MMM")
+		default:
+			if !strings.HasPrefix(text, token) {
+				return fail(fmt.Sprintf("expected %q", token))
+			}
+			text = text[len(token):]
+			continue
+		}
+		if err != nil {
+			return fail(err.Error())
+		}
+		switch token[0] {
+		case 'M':
+			m = n
+		case 'D':
+			d = n
+		}
+	}
+	if text != "" {
+		return fail(fmt.Sprintf("extra text %q", text))
+	}
+	return y, m, d, nil
+}
+
+// Read between min and max digits from the start of s
+func readDigits(s string, min, max int) (int, string, error) {
+	n := 0
+	for n < max && n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	if n < min {
+		return 0, s, fmt.Errorf("expected %d digits", min)
+	}
+	value, err := strconv.Atoi(s[:n])
+	return value, s[n:], err
+}
+
+// Read a month name, either full or its first three
+// letters, ignoring case
+func readMonthName(s string, short bool) (int, string, error) {
+	for m := 1; m <= 12; m++ {
+		name := monthName(m)
+		if short {
+			name = shortMonthName(m)
+		}
+		if len(s) >= len(name) && strings.EqualFold(s[:len(name)], name) {
+			return m, s[len(name):], nil
+		}
+	}
+	return 0, s, fmt.Errorf("expected a month name")
+}
+
+// Turn a two digit year into the latest year ending
+// in those digits that isn't after now
+func expandYear(yy int, now time.Time) int {
+	thisYear := now.Year()
+	year := thisYear - thisYear%100 + yy
+	if year > thisYear {
+		year -= 100
+	}
+	return year
+}
+
+// MarshalText writes the date in ISO 8601 so it can be
+// used in config files. A zero date is empty
+func (d Date) MarshalText() ([]byte, error) {
+	if d.IsZero() {
+		return []byte{}, nil
+	}
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText reads an ISO 8601 date. Empty text
+// gives a zero date. The date's policy is kept, and a
+// date without one is checked against the package
+// default, see SetDefaultPolicy
+func (d *Date) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*d = Date{policy: d.policy}
+		return nil
+	}
+	date, err := d.parse(ISOLayout, string(text))
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+// MarshalJSON writes the date as an ISO 8601 string,
+// or null for a zero date
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON reads an ISO 8601 string or null
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Date{policy: d.policy}
+		return nil
+	}
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	return d.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,353 @@
+package stuff
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HolidayCalendar knows which days are weekends and
+// holidays. It is read from a file with one rule on
+// each line and # starting a comment:
+//
+//	weekend SA SU
+//	fixed 12-25 Christmas Day
+//	nth 4 TH 11 Thanksgiving
+//	nth -1 MO 05 Memorial Day
+//	easter -2 Good Friday
+//
+// nth picks the nth weekday of a month, counting from
+// the end when n is negative. easter is some days from
+// Easter Sunday. Without a weekend line the weekend is
+// Saturday and Sunday
+type HolidayCalendar struct {
+	weekend [7]bool
+	rules   []holidayRule
+
+	// The holidays of each year asked for, worked out once
+	mu    sync.Mutex
+	years map[int][]Holiday
+}
+
+// Holiday is a named day off
+type Holiday struct {
+	Date Date
+	Name string
+}
+
+// The kinds of rule a holiday can have
+const (
+	ruleFixed  = "fixed"
+	ruleNth    = "nth"
+	ruleEaster = "easter"
+)
+
+// One line of the holiday file
+type holidayRule struct {
+	kind    string
+	name    string
+	month   int
+	day     int
+	n       int
+	weekday time.Weekday
+	offset  int
+}
+
+// The weekend used when a calendar doesn't say, or
+// when no calendar is given
+var defaultWeekend = [7]bool{time.Saturday: true, time.Sunday: true}
+
+// Read a holiday calendar from a file
+func LoadHolidays(path string) (*HolidayCalendar, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseHolidays(file)
+}
+
+// Read a holiday calendar, see HolidayCalendar for
+// the format
+func ParseHolidays(r io.Reader) (*HolidayCalendar, error) {
+	cal := &HolidayCalendar{weekend: defaultWeekend}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		err := cal.parseLine(fields)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return cal, scanner.Err()
+}
+
+// Add the rule on one line of the file
+func (cal *HolidayCalendar) parseLine(fields []string) error {
+	rule := holidayRule{kind: fields[0]}
+	var err error
+	switch fields[0] {
+	case "weekend":
+		// A day named twice is only counted once
+		cal.weekend = [7]bool{}
+		days := 0
+		for _, name := range fields[1:] {
+			day := indexOf(ruleDays, strings.ToUpper(name))
+			if day < 0 {
+				return fmt.Errorf("%q is not a day like SA", name)
+			}
+			if !cal.weekend[day] {
+				cal.weekend[day] = true
+				days++
+			}
+		}
+		// Adding business days would never finish
+		if days == 7 {
+			return fmt.Errorf("the weekend can't be every day")
+		}
+		return nil
+	case ruleFixed:
+		if len(fields) < 2 {
+			return fmt.Errorf("fixed needs a date like 12-25")
+		}
+		rule.month, rule.day, err = parseMonthDay(fields[1])
+		rule.name = strings.Join(fields[2:], " ")
+	case ruleNth:
+		if len(fields) < 4 {
+			return fmt.Errorf("nth needs a count, a day and a month like 4 TH 11")
+		}
+		rule.n, err = strconv.Atoi(fields[1])
+		if err == nil && (rule.n == 0 || rule.n < -5 || rule.n > 5) {
+			err = fmt.Errorf("count %d must be from 1 to 5 or -5 to -1", rule.n)
+		}
+		if day := indexOf(ruleDays, strings.ToUpper(fields[2])); day >= 0 {
+			rule.weekday = time.Weekday(day)
+		} else if err == nil {
+			err = fmt.Errorf("%q is not a day like MO", fields[2])
+		}
+		if err == nil {
+			rule.month, err = strconv.Atoi(fields[3])
+		}
+		if err == nil && (rule.month < 1 || rule.month > 12) {
+			err = &DateError{FieldMonth, rule.month, "must be from 1 to 12"}
+		}
+		rule.name = strings.Join(fields[4:], " ")
+	case ruleEaster:
+		if len(fields) < 2 {
+			return fmt.Errorf("easter needs an offset in days like -2")
+		}
+		rule.offset, err = strconv.Atoi(fields[1])
+		rule.name = strings.Join(fields[2:], " ")
+	default:
+		return fmt.Errorf("unknown rule %q", fields[0])
+	}
+	if err != nil {
+		return err
+	}
+	cal.rules = append(cal.rules, rule)
+	return nil
+}
+
+// Read a month and day like 12-25. 02-29 is allowed
+// and only happens in leap years, so the days are
+// checked against 2000 which is one
+func parseMonthDay(s string) (int, int, error) {
+	_, m, d, err := parseFields("MM-DD", s, time.Now())
+	if err != nil {
+		return 0, 0, err
+	}
+	if (m < 1) || (m > 12) {
+		return 0, 0, &DateError{FieldMonth, m, "must be from 1 to 12"}
+	}
+	if (d < 1) || (d > daysIn(2000, m)) {
+		return 0, 0, &DateError{FieldDay, d, fmt.Sprintf("%s has at most %d days",
+			time.Month(m), daysIn(2000, m))}
+	}
+	return m, d, nil
+}
+
+// Return the day a rule falls on in a year. Some
+// rules don't happen every year, like 29 February or a
+// fifth Monday
+func (rule holidayRule) date(year int) (Date, bool) {
+	switch rule.kind {
+	case ruleFixed:
+		if rule.day > daysIn(year, rule.month) {
+			return Date{}, false
+		}
+		return Date{day: rule.day, month: rule.month, year: year}, true
+	case ruleNth:
+		if rule.n > 0 {
+			first := Date{day: 1, month: rule.month, year: year}
+			day := 1 + (int(rule.weekday)-int(first.Weekday())+7)%7 + (rule.n-1)*7
+			if day > daysIn(year, rule.month) {
+				return Date{}, false
+			}
+			return Date{day: day, month: rule.month, year: year}, true
+		}
+		last := Date{day: daysIn(year, rule.month), month: rule.month, year: year}
+		day := last.day - (int(last.Weekday())-int(rule.weekday)+7)%7 + (rule.n+1)*7
+		if day < 1 {
+			return Date{}, false
+		}
+		return Date{day: day, month: rule.month, year: year}, true
+	}
+	return dateFromSerial(easter(year).serial() + rule.offset), true
+}
+
+// Work out Easter Sunday in the Gregorian calendar
+// with the anonymous algorithm from Nature in 1876
+func easter(year int) Date {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return Date{day: day, month: month, year: year}
+}
+
+// Return the holidays in a year in date order. They are
+// checked against the package default policy, allowing
+// the future since holidays are often still to come, so
+// a year before 1875 gives an error with Birthdate
+func (cal *HolidayCalendar) Holidays(year int) ([]Holiday, error) {
+	var holidays []Holiday
+	for _, holiday := range cal.holidays(year) {
+		day := holiday.Date
+		date, err := Date{}.ahead().withDate(day.year, day.month, day.day)
+		if err != nil {
+			return nil, fmt.Errorf("holidays in %d: %w", year, err)
+		}
+		holidays = append(holidays, Holiday{date, holiday.Name})
+	}
+	return holidays, nil
+}
+
+// Return the holidays in a year in date order without
+// checking them against a policy. Each year is only
+// worked out once, the result must not be changed
+func (cal *HolidayCalendar) holidays(year int) []Holiday {
+	cal.mu.Lock()
+	defer cal.mu.Unlock()
+	if holidays, ok := cal.years[year]; ok {
+		return holidays
+	}
+	var holidays []Holiday
+	for _, rule := range cal.rules {
+		// Easter rules can move into the year before or
+		// after, so look at those years too
+		for y := year - 1; y <= year+1; y++ {
+			day, ok := rule.date(y)
+			if ok && day.year == year {
+				holidays = append(holidays, Holiday{day, rule.name})
+			}
+		}
+	}
+	sort.SliceStable(holidays, func(i, j int) bool {
+		return holidays[i].Date.Before(holidays[j].Date)
+	})
+	if cal.years == nil {
+		cal.years = map[int][]Holiday{}
+	}
+	cal.years[year] = holidays
+	return holidays
+}
+
+// Return the name of the holiday on a day
+func (cal *HolidayCalendar) Holiday(d Date) (string, bool) {
+	if cal == nil {
+		return "", false
+	}
+	for _, holiday := range cal.holidays(d.year) {
+		if holiday.Date.Equal(d) {
+			return holiday.Name, true
+		}
+	}
+	return "", false
+}
+
+// Check if a day is on the weekend. A nil calendar
+// has a Saturday and Sunday weekend and no holidays
+func (cal *HolidayCalendar) IsWeekend(d Date) bool {
+	weekend := defaultWeekend
+	if cal != nil {
+		weekend = cal.weekend
+	}
+	return weekend[d.Weekday()]
+}
+
+// Check if a day is a working day in a calendar, so
+// not on the weekend or a holiday
+func (d Date) IsBusinessDay(cal *HolidayCalendar) bool {
+	if cal.IsWeekend(d) {
+		return false
+	}
+	_, holiday := cal.Holiday(d)
+	return !holiday
+}
+
+// Return the date n working days later, or earlier if
+// n is negative. Adding 5 business days to a Friday
+// gives the next Friday if there are no holidays.
+// The result keeps d's policy but is allowed to be in
+// the future, so a birth date works too
+func (d Date) AddBusinessDays(n int, cal *HolidayCalendar) (Date, error) {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	day := d
+	for n > 0 {
+		day = dateFromSerial(day.serial() + step)
+		if day.year < firstYear || day.year > lastYear {
+			break
+		}
+		if day.IsBusinessDay(cal) {
+			n--
+		}
+	}
+	return d.ahead().withDate(day.year, day.month, day.day)
+}
+
+// Return how many working days there are after d up to
+// and including other. It is negative if other is
+// earlier, so d.AddBusinessDays of the result gives
+// other back when other is a business day
+func (d Date) BusinessDaysBetween(other Date, cal *HolidayCalendar) int {
+	from, to, sign := d, other, 1
+	if other.Before(d) {
+		from, to, sign = other, d, -1
+	}
+	count := 0
+	for day := from.next(); !day.After(to); day = day.next() {
+		if day.IsBusinessDay(cal) {
+			count++
+		}
+	}
+	// Going backwards counts the days before d
+	if sign < 0 {
+		if to.IsBusinessDay(cal) {
+			count--
+		}
+		if from.IsBusinessDay(cal) {
+			count++
+		}
+	}
+	return sign * count
+}
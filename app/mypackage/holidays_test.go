@@ -0,0 +1,190 @@
+package stuff
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const usHolidays = `
+# Some US federal holidays
+fixed 01-01 New Year's Day
+nth 3 MO 01 Martin Luther King Jr. Day
+nth -1 MO 05 Memorial Day
+fixed 07-04 Independence Day
+nth 4 TH 11 Thanksgiving
+fixed 12-25 Christmas Day
+easter -2 Good Friday   # not federal, but markets close
+`
+
+func TestHolidays(t *testing.T) {
+	cal, err := ParseHolidays(strings.NewReader(usHolidays))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	holidays, err := cal.Holidays(2024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, holiday := range holidays {
+		got = append(got, holiday.Date.String()+" "+holiday.Name)
+	}
+	want := []string{
+		"2024-01-01 New Year's Day",
+		"2024-01-15 Martin Luther King Jr. Day",
+		"2024-03-29 Good Friday",
+		"2024-05-27 Memorial Day",
+		"2024-07-04 Independence Day",
+		"2024-11-28 Thanksgiving",
+		"2024-12-25 Christmas Day",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Holidays(2024) =\n%v\nwant\n%v", got, want)
+	}
+	if name, ok := cal.Holiday(mustParse(t, "2024-11-28")[0]); !ok || name != "Thanksgiving" {
+		t.Errorf("Holiday = %q %v", name, ok)
+	}
+}
+
+func TestEaster(t *testing.T) {
+	for _, want := range []string{"1875-03-28", "1943-04-25", "2000-04-23",
+		"2019-04-21", "2024-03-31", "2025-04-20", "2038-04-25"} {
+		date := mustParse(t, want)[0]
+		if got := easter(date.Year()); !got.Equal(date) {
+			t.Errorf("easter(%d) = %v, want %s", date.Year(), got, want)
+		}
+	}
+}
+
+func TestBusinessDays(t *testing.T) {
+	cal, err := ParseHolidays(strings.NewReader(usHolidays))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		from string
+		n    int
+		cal  *HolidayCalendar
+		want string
+	}{
+		{"2024-06-07", 5, nil, "2024-06-14"},  // Friday to Friday
+		{"2024-06-08", 1, nil, "2024-06-10"},  // Saturday to Monday
+		{"2024-06-10", -1, nil, "2024-06-07"}, // Monday to Friday
+		{"2024-06-10", 0, nil, "2024-06-10"},
+		{"2024-11-27", 1, cal, "2024-11-29"},  // over Thanksgiving
+		{"2024-12-24", 1, cal, "2024-12-26"},  // over Christmas
+		{"2024-04-01", -1, cal, "2024-03-28"}, // back over Good Friday
+	}
+	for _, test := range tests {
+		from := mustParse(t, test.from)[0]
+		got, err := from.AddBusinessDays(test.n, test.cal)
+		if err != nil {
+			t.Errorf("%s + %d: %v", test.from, test.n, err)
+			continue
+		}
+		if got.String() != test.want {
+			t.Errorf("%s + %d business days = %v, want %s", test.from, test.n, got, test.want)
+		}
+		if between := from.BusinessDaysBetween(got, test.cal); between != test.n {
+			t.Errorf("BusinessDaysBetween(%s, %v) = %d, want %d", test.from, got, between, test.n)
+		}
+	}
+	if mustParse(t, "2024-12-25")[0].IsBusinessDay(cal) {
+		t.Error("Christmas is a business day")
+	}
+	if !mustParse(t, "2024-12-25")[0].IsBusinessDay(nil) {
+		t.Error("a Wednesday with no calendar isn't a business day")
+	}
+}
+
+func TestWeekendLine(t *testing.T) {
+	cal, err := ParseHolidays(strings.NewReader("weekend FR SA\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	thursday := mustParse(t, "2024-06-06")[0]
+	got, _ := thursday.AddBusinessDays(1, cal)
+	if got.String() != "2024-06-09" {
+		t.Errorf("Thursday + 1 with a Friday and Saturday weekend = %v", got)
+	}
+	// Naming a day twice doesn't make the week all weekend
+	if _, err := ParseHolidays(strings.NewReader("weekend SA SA SU SU MO MO TU\n")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseHolidaysErrors(t *testing.T) {
+	bad := []string{
+		"fixed 02-30 Nope",
+		"fixed 13-01",
+		"fixed",
+		"nth 0 MO 01",
+		"nth 1 XX 01",
+		"nth 1 MO 13",
+		"easter soon",
+		"weekend SU MO TU WE TH FR SA",
+		"weekend SU SU MO TU WE TH FR SA",
+		"party 01-01",
+	}
+	for _, text := range bad {
+		_, err := ParseHolidays(strings.NewReader("# ok\n" + text))
+		if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+			t.Errorf("%q: got %v", text, err)
+		}
+	}
+}
+
+func TestLoadHolidays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holidays.txt")
+	err := os.WriteFile(path, []byte(usHolidays), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cal, err := LoadHolidays(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holidays, err := cal.Holidays(2025); err != nil || len(holidays) != 7 {
+		t.Errorf("got %d holidays, %v", len(holidays), err)
+	}
+}
+
+// Business days and holidays from a date that can't be
+// in the future can still be after today
+func TestBusinessDaysIntoTheFuture(t *testing.T) {
+	cal, err := ParseHolidays(strings.NewReader(usHolidays))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	today, err := NewDate(now.Year(), int(now.Month()), now.Day())
+	if err != nil {
+		t.Fatal(err)
+	}
+	later, err := today.AddBusinessDays(10, cal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !later.After(today) || later.Policy().Min.IsZero() {
+		t.Errorf("today + 10 business days = %v with policy %+v", later, later.Policy())
+	}
+	holidays, err := cal.Holidays(now.Year() + 5)
+	if err != nil || len(holidays) == 0 {
+		t.Fatalf("no holidays in %d: %v", now.Year()+5, err)
+	}
+	if _, err := holidays[0].Date.AddDays(1); err != nil {
+		t.Error(err)
+	}
+	if got, err := cal.Holidays(1870); err == nil {
+		t.Errorf("holidays before 1875 are %v", got)
+	}
+	// Looking up a day still works in any year
+	christmas, _ := Unbounded().NewDate(1870, 12, 25)
+	if name, ok := cal.Holiday(christmas); !ok || name != "Christmas Day" {
+		t.Errorf("Holiday(%v) = %q, %v", christmas, name, ok)
+	}
+}
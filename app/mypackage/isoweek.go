@@ -0,0 +1,113 @@
+package stuff
+
+import "fmt"
+
+// Return the ISO 8601 day of the week, from 1 for
+// Monday to 7 for Sunday
+func (d Date) isoWeekday() int {
+	return (int(d.Weekday())+6)%7 + 1
+}
+
+// Return how many ISO weeks a year has. Years that
+// start on a Thursday, or leap years that start on a
+// Wednesday, have 53
+func ISOWeeksIn(year int) int {
+	jan1 := Date{day: 1, month: 1, year: year}.isoWeekday()
+	if jan1 == 4 || (jan1 == 3 && isLeap(year)) {
+		return 53
+	}
+	return 52
+}
+
+// Return the date as an ISO 8601 week date like
+// 2024-W03-2. The year is the ISO year, which can be
+// different from the calendar year in late December
+// and early January
+func (d Date) ISOWeekDate() string {
+	year, week := d.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d-%d", year, week, d.isoWeekday())
+}
+
+// Return the date as an ISO 8601 ordinal date like
+// 2024-017
+func (d Date) OrdinalDate() string {
+	return fmt.Sprintf("%04d-%03d", d.year, d.DayOfYear())
+}
+
+// Create a date from an ISO year, week and day of the
+// week from 1 for Monday to 7 for Sunday. Like the
+// other conversions the date has the Unbounded policy
+func FromISOWeek(year, week, weekday int) (Date, error) {
+	if (week < 1) || (week > ISOWeeksIn(year)) {
+		return Date{}, &DateError{FieldWeek, week,
+			fmt.Sprintf("%d has %d ISO weeks", year, ISOWeeksIn(year))}
+	}
+	if (weekday < 1) || (weekday > 7) {
+		return Date{}, &DateError{FieldDay, weekday, "day of the week must be from 1 to 7"}
+	}
+	// Week 1 is the week with 4 January in it
+	jan4 := Date{day: 4, month: 1, year: year}
+	monday := jan4.serial() - (jan4.isoWeekday() - 1)
+	return Unbounded().NewDate(fromSerial(monday + (week-1)*7 + weekday - 1))
+}
+
+// Create a date from a year and a day of the year from
+// 1 to 365, or 366 in leap years. The date has the
+// Unbounded policy
+func FromOrdinal(year, day int) (Date, error) {
+	days := 365
+	if isLeap(year) {
+		days = 366
+	}
+	if (day < 1) || (day > days) {
+		return Date{}, &DateError{FieldDay, day, fmt.Sprintf("%d has %d days", year, days)}
+	}
+	return Unbounded().NewDate(fromSerial(Date{day: 1, month: 1, year: year}.serial() + day - 1))
+}
+
+// Read an ISO 8601 week date like 2024-W03-2, or the
+// basic form 2024W032
+func ParseISOWeek(s string) (Date, error) {
+	basic := s
+	if len(s) == 10 && s[4] == '-' && s[8] == '-' {
+		basic = s[:4] + s[5:8] + s[9:]
+	}
+	if len(basic) != 8 || basic[4] != 'W' {
+		return Date{}, &ParseError{Layout: "YYYY-Www-D", Value: s, Reason: "not an ISO week date"}
+	}
+	numbers, ok := readNumbers(basic[:4], basic[5:7], basic[7:])
+	if !ok {
+		return Date{}, &ParseError{Layout: "YYYY-Www-D", Value: s, Reason: "expected digits"}
+	}
+	return FromISOWeek(numbers[0], numbers[1], numbers[2])
+}
+
+// Read an ISO 8601 ordinal date like 2024-017, or the
+// basic form 2024017
+func ParseOrdinal(s string) (Date, error) {
+	basic := s
+	if len(s) == 8 && s[4] == '-' {
+		basic = s[:4] + s[5:]
+	}
+	if len(basic) != 7 {
+		return Date{}, &ParseError{Layout: "YYYY-DDD", Value: s, Reason: "not an ordinal date"}
+	}
+	numbers, ok := readNumbers(basic[:4], basic[4:])
+	if !ok {
+		return Date{}, &ParseError{Layout: "YYYY-DDD", Value: s, Reason: "expected digits"}
+	}
+	return FromOrdinal(numbers[0], numbers[1])
+}
+
+// Read some numbers that must be all digits
+func readNumbers(parts ...string) ([]int, bool) {
+	var numbers []int
+	for _, part := range parts {
+		n, rest, err := readDigits(part, len(part), len(part))
+		if err != nil || rest != "" || part == "" {
+			return nil, false
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, true
+}
@@ -0,0 +1,106 @@
+package stuff
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestISOWeekDate(t *testing.T) {
+	tests := []struct{ date, week, ordinal string }{
+		{"2024-01-16", "2024-W03-2", "2024-016"},
+		{"2024-01-17", "2024-W03-3", "2024-017"},
+		{"2024-12-31", "2025-W01-2", "2024-366"},
+		{"2021-01-03", "2020-W53-7", "2021-003"},
+		{"2020-12-31", "2020-W53-4", "2020-366"},
+		{"2015-12-31", "2015-W53-4", "2015-365"},
+		{"2010-01-03", "2009-W53-7", "2010-003"},
+		{"2008-12-29", "2009-W01-1", "2008-364"},
+		{"1974-12-21", "1974-W51-6", "1974-355"},
+	}
+	for _, test := range tests {
+		date := mustParse(t, test.date)[0]
+		if got := date.ISOWeekDate(); got != test.week {
+			t.Errorf("%s ISOWeekDate = %s, want %s", test.date, got, test.week)
+		}
+		if got := date.OrdinalDate(); got != test.ordinal {
+			t.Errorf("%s OrdinalDate = %s, want %s", test.date, got, test.ordinal)
+		}
+		if got, err := ParseISOWeek(test.week); err != nil || !got.Equal(date) {
+			t.Errorf("ParseISOWeek(%s) = %v, %v", test.week, got, err)
+		}
+		if got, err := ParseOrdinal(test.ordinal); err != nil || !got.Equal(date) {
+			t.Errorf("ParseOrdinal(%s) = %v, %v", test.ordinal, got, err)
+		}
+	}
+	// The basic forms without dashes
+	if got, err := ParseISOWeek("2024W032"); err != nil || got.String() != "2024-01-16" {
+		t.Errorf("ParseISOWeek(2024W032) = %v, %v", got, err)
+	}
+	if got, err := ParseOrdinal("2024017"); err != nil || got.String() != "2024-01-17" {
+		t.Errorf("ParseOrdinal(2024017) = %v, %v", got, err)
+	}
+}
+
+// Every day's week date should round trip and agree
+// with time.Time about the number of weeks in a year
+func TestISOWeekRoundTrip(t *testing.T) {
+	policy := Policy{AllowFuture: true}
+	start, _ := policy.NewDate(1900, 1, 1)
+	end, _ := policy.NewDate(2100, 12, 31)
+	r, _ := NewDateRange(start, end)
+	r.Each(func(d Date) bool {
+		year, week := d.ISOWeek()
+		back, err := FromISOWeek(year, week, d.isoWeekday())
+		if err != nil || !back.Equal(d) {
+			t.Errorf("%v as %s came back as %v, %v", d, d.ISOWeekDate(), back, err)
+			return false
+		}
+		back, err = FromOrdinal(d.year, d.DayOfYear())
+		if err != nil || !back.Equal(d) {
+			t.Errorf("%v as %s came back as %v, %v", d, d.OrdinalDate(), back, err)
+			return false
+		}
+		return true
+	})
+	for year := 1900; year <= 2100; year++ {
+		_, weeks := time.Date(year, 12, 28, 0, 0, 0, 0, time.UTC).ISOWeek()
+		if ISOWeeksIn(year) != weeks {
+			t.Errorf("ISOWeeksIn(%d) = %d, want %d", year, ISOWeeksIn(year), weeks)
+		}
+	}
+}
+
+func TestISOWeekErrors(t *testing.T) {
+	var dateErr *DateError
+	if _, err := FromISOWeek(2021, 53, 1); !errors.As(err, &dateErr) || dateErr.Field != FieldWeek {
+		t.Errorf("2021 has no week 53: %v", err)
+	}
+	if _, err := FromISOWeek(2020, 53, 1); err != nil {
+		t.Errorf("2020 has a week 53: %v", err)
+	}
+	if _, err := FromISOWeek(2024, 1, 8); err == nil {
+		t.Error("allowed day 8 of a week")
+	}
+	// Dates are not limited to the past or to 1875 on
+	if got, err := ParseISOWeek("2026-W53-1"); err != nil || got.String() != "2026-12-28" {
+		t.Errorf("ParseISOWeek(2026-W53-1) = %v, %v", got, err)
+	}
+	if got, err := FromISOWeek(2027, 1, 1); err != nil || got.String() != "2027-01-04" {
+		t.Errorf("FromISOWeek(2027, 1, 1) = %v, %v", got, err)
+	}
+	if got, err := FromOrdinal(1800, 10); err != nil || got.String() != "1800-01-10" {
+		t.Errorf("FromOrdinal(1800, 10) = %v, %v", got, err)
+	}
+	if _, err := FromOrdinal(2023, 366); err == nil {
+		t.Error("allowed day 366 of 2023")
+	}
+	for _, s := range []string{"2024-W3-2", "2024-W03", "2024-w03-2", "2024-W0a-2", "24-017", "2024-17", "2024-0170"} {
+		if _, err := ParseISOWeek(s); err == nil {
+			t.Errorf("ParseISOWeek(%q) succeeded", s)
+		}
+		if _, err := ParseOrdinal(s); err == nil {
+			t.Errorf("ParseOrdinal(%q) succeeded", s)
+		}
+	}
+}
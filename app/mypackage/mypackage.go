@@ -1,12 +1,56 @@
 package stuff
 
 import (
-	"errors"
+	"fmt"
+	"os"
+	"os/user"
 	"strconv"
-	"time"
+	"sync"
 )
 
-var Name string = "Derek"
+// Name is who to greet. Leave it empty to greet the
+// user running the program, see GreetName
+var Name string
+
+// How the user's name is looked up, tests replace them
+var (
+	getenv      = os.Getenv
+	currentUser = user.Current
+)
+
+// The looked up name, found the first time it is needed
+// so importing the package doesn't look up the user
+var userName struct {
+	once sync.Once
+	name string
+}
+
+// Return Name, or if it is empty the name of the user
+// running the program
+func GreetName() string {
+	if Name != "" {
+		return Name
+	}
+	userName.once.Do(func() { userName.name = lookupName() })
+	return userName.name
+}
+
+// Use the NAME environment variable if it is set, then
+// the user's full name or login, then World
+func lookupName() string {
+	if name := getenv("NAME"); name != "" {
+		return name
+	}
+	if u, err := currentUser(); err == nil {
+		if u.Name != "" {
+			return u.Name
+		}
+		if u.Username != "" {
+			return u.Username
+		}
+	}
+	return "World"
+}
 
 // Function name is uppercase so it can be exported
 func IntArrToStrArr(intArr []int) []string {
@@ -25,28 +69,97 @@ type Date struct {
 	day   int
 	month int
 	year  int
+	// Which dates are allowed, unset for the package
+	// default
+	policy dateRules
+}
+
+// The parts of a date an error can be about
+type DateField string
+
+const (
+	FieldDay   DateField = "day"
+	FieldMonth DateField = "month"
+	FieldYear  DateField = "year"
+	FieldWeek  DateField = "week"
+)
+
+// DateError says which part of a date is wrong and why.
+// Use errors.As to get at the field
+type DateError struct {
+	Field  DateField
+	Value  int
+	Reason string
+}
+
+func (e *DateError) Error() string {
+	return fmt.Sprintf("incorrect %s value %d: %s", e.Field, e.Value, e.Reason)
+}
+
+// The earliest year Birthdate and Scheduling allow
+const minYear = 1875
+
+// Leap years are divisible by 4, except centuries
+// that aren't divisible by 400
+func isLeap(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// Return the number of days in a month
+func daysIn(year, month int) int {
+	switch month {
+	case 2:
+		if isLeap(year) {
+			return 29
+		}
+		return 28
+	case 4, 6, 9, 11:
+		return 30
+	}
+	return 31
+}
+
+// Create a date, checking the year, month and day
+// together so 31 February or 29 February 2023 are
+// rejected. The date is checked against the package
+// default policy, Birthdate unless it was changed
+func NewDate(y, m, d int) (Date, error) {
+	return Date{}.withDate(y, m, d)
+}
+
+// Set the whole date at once. If it isn't valid the
+// date is left unchanged
+func (d *Date) Set(y, m, day int) error {
+	date, err := d.withDate(y, m, day)
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
 }
 
 // Create a setter function for the values
 // Make sure all values are valid or return
-// an error message
+// an error message. The setters only check one
+// field at a time, use Set to check the whole date
 func (d *Date) SetDay(day int) error {
 	if (day < 1) || (day > 31) {
-		return errors.New("incorrect day value")
+		return &DateError{FieldDay, day, "must be from 1 to 31"}
 	}
 	d.day = day
 	return nil
 }
 func (d *Date) SetMonth(m int) error {
 	if (m < 1) || (m > 12) {
-		return errors.New("incorrect month value")
+		return &DateError{FieldMonth, m, "must be from 1 to 12"}
 	}
 	d.month = m
 	return nil
 }
 func (d *Date) SetYear(y int) error {
-	if (y < 1875) || (y > time.Now().Year()) {
-		return errors.New("incorrect year value")
+	err := d.rules().validateYear(y)
+	if err != nil {
+		return err
 	}
 	d.year = y
 	return nil
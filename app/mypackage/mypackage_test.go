@@ -0,0 +1,45 @@
+package stuff
+
+import (
+	"errors"
+	"os/user"
+	"testing"
+)
+
+func TestLookupNameFallbacks(t *testing.T) {
+	savedEnv, savedUser := getenv, currentUser
+	defer func() { getenv, currentUser = savedEnv, savedUser }()
+
+	tests := []struct {
+		env  string
+		user *user.User
+		want string
+	}{
+		{"Ada", &user.User{Name: "Grace Hopper", Username: "grace"}, "Ada"},
+		{"", &user.User{Name: "Grace Hopper", Username: "grace"}, "Grace Hopper"},
+		{"", &user.User{Username: "grace"}, "grace"},
+		{"", &user.User{}, "World"},
+		{"", nil, "World"},
+	}
+	for _, test := range tests {
+		getenv = func(string) string { return test.env }
+		currentUser = func() (*user.User, error) {
+			if test.user == nil {
+				return nil, errors.New("no user")
+			}
+			return test.user, nil
+		}
+		if got := lookupName(); got != test.want {
+			t.Errorf("NAME %q and user %+v gave %q, want %q", test.env, test.user, got, test.want)
+		}
+	}
+}
+
+func TestGreetNamePrefersName(t *testing.T) {
+	saved := Name
+	defer func() { Name = saved }()
+	Name = "Derek"
+	if got := GreetName(); got != "Derek" {
+		t.Errorf("GreetName() = %q, want Derek", got)
+	}
+}
@@ -0,0 +1,409 @@
+package stuff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keyword is what a word means in a date expression
+type Keyword int
+
+const (
+	KeyToday Keyword = iota + 1
+	KeyTomorrow
+	KeyYesterday
+	KeyThis
+	KeyNext
+	KeyLast
+	KeyIn
+	KeyAgo
+	// Words like "on" and "the" that are skipped
+	KeyIgnore
+)
+
+// Unit is a length of time a date can move by
+type Unit int
+
+const (
+	UnitDay Unit = iota + 1
+	UnitWeek
+	UnitMonth
+	UnitYear
+)
+
+// NumericOrder says how to read a date like 3/4
+type NumericOrder int
+
+const (
+	// 3/4 could be either, so it is reported as
+	// ambiguous unless only one reading is a real date
+	EitherOrder NumericOrder = iota
+	MonthFirst
+	DayFirst
+)
+
+// Words is the vocabulary of one language. All the
+// words are lower case
+type Words struct {
+	Keywords map[string]Keyword
+	Weekdays map[string]time.Weekday
+	Months   map[string]int
+	Units    map[string]Unit
+	Numbers  map[string]int
+	Order    NumericOrder
+}
+
+// English words, with short forms of days and months
+var English = Words{
+	Keywords: map[string]Keyword{
+		"today": KeyToday, "tomorrow": KeyTomorrow, "yesterday": KeyYesterday,
+		"this": KeyThis, "next": KeyNext, "last": KeyLast, "in": KeyIn,
+		"ago": KeyAgo, "on": KeyIgnore, "the": KeyIgnore, "of": KeyIgnore,
+	},
+	Weekdays: map[string]time.Weekday{
+		"sunday": time.Sunday, "sun": time.Sunday,
+		"monday": time.Monday, "mon": time.Monday,
+		"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+		"wednesday": time.Wednesday, "wed": time.Wednesday,
+		"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+		"friday": time.Friday, "fri": time.Friday,
+		"saturday": time.Saturday, "sat": time.Saturday,
+	},
+	Months: map[string]int{
+		"january": 1, "jan": 1, "february": 2, "feb": 2, "march": 3, "mar": 3,
+		"april": 4, "apr": 4, "may": 5, "june": 6, "jun": 6, "july": 7, "jul": 7,
+		"august": 8, "aug": 8, "september": 9, "sep": 9, "sept": 9,
+		"october": 10, "oct": 10, "november": 11, "nov": 11, "december": 12, "dec": 12,
+	},
+	Units: map[string]Unit{
+		"day": UnitDay, "days": UnitDay, "week": UnitWeek, "weeks": UnitWeek,
+		"month": UnitMonth, "months": UnitMonth, "year": UnitYear, "years": UnitYear,
+	},
+	Numbers: map[string]int{
+		"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+		"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+	},
+	Order: MonthFirst,
+}
+
+// AmbiguousError is returned when an expression could
+// mean more than one date
+type AmbiguousError struct {
+	Input   string
+	Choices []Date
+}
+
+func (e *AmbiguousError) Error() string {
+	var choices []string
+	for _, choice := range e.Choices {
+		choices = append(choices, choice.String())
+	}
+	return fmt.Sprintf("%q is ambiguous, it could be %s", e.Input, strings.Join(choices, " or "))
+}
+
+// Read an English date expression like "tomorrow",
+// "next friday", "in 3 weeks", "2 days ago" or
+// "Dec 21" relative to ref
+func ParseNatural(s string, ref Date) (Date, error) {
+	return English.Parse(s, ref)
+}
+
+// Read a date expression relative to ref. The result
+// has ref's bounds but may be in the future even if
+// ref's policy doesn't allow that, so "tomorrow" works
+// from any date. Expressions it understands are:
+//
+//	today, tomorrow, yesterday
+//	friday, this friday, next friday, last friday
+//	next week, last month, this year
+//	in 3 days, in a week, 2 months ago
+//	Dec 21, 21 December, Dec 21 1974, 12/21, 12/21/1974
+//
+// A day and month without a year is the next time that
+// day comes, counting ref
+func (w *Words) Parse(s string, ref Date) (Date, error) {
+	var tokens []string
+	for _, token := range strings.Fields(strings.ToLower(strings.ReplaceAll(s, ",", " "))) {
+		token = strings.TrimSuffix(token, ".")
+		if w.Keywords[token] != KeyIgnore {
+			tokens = append(tokens, token)
+		}
+	}
+	p := naturalParse{w, s, ref.ahead()}
+	for _, parse := range []func([]string) (Date, bool, error){
+		p.keyword, p.weekday, p.relative, p.offset, p.monthDay, p.numeric,
+	} {
+		date, ok, err := parse(tokens)
+		if ok || err != nil {
+			return date, err
+		}
+	}
+	// An ISO date is understood in any language
+	date, err := p.ref.parse(ISOLayout, strings.TrimSpace(s))
+	if err != nil {
+		return Date{}, fmt.Errorf("cannot understand the date %q", s)
+	}
+	return date, nil
+}
+
+// What one parse needs to know. Each method tries to
+// read the tokens one way, returning false if they
+// don't fit that way
+type naturalParse struct {
+	words *Words
+	input string
+	ref   Date
+}
+
+// today, tomorrow or yesterday
+func (p naturalParse) keyword(tokens []string) (Date, bool, error) {
+	if len(tokens) != 1 {
+		return Date{}, false, nil
+	}
+	days := map[Keyword]int{KeyToday: 0, KeyTomorrow: 1, KeyYesterday: -1}
+	n, ok := days[p.words.Keywords[tokens[0]]]
+	if !ok {
+		return Date{}, false, nil
+	}
+	date, err := p.ref.AddDays(n)
+	return date, true, err
+}
+
+// friday on its own is the next one. If ref is a
+// Friday that could be today or a week away
+func (p naturalParse) weekday(tokens []string) (Date, bool, error) {
+	if len(tokens) != 1 {
+		return Date{}, false, nil
+	}
+	day, ok := p.words.Weekdays[tokens[0]]
+	if !ok {
+		return Date{}, false, nil
+	}
+	ahead := (int(day) - int(p.ref.Weekday()) + 7) % 7
+	if ahead == 0 {
+		return p.ambiguous(0, 7)
+	}
+	date, err := p.ref.AddDays(ahead)
+	return date, true, err
+}
+
+// this, next or last with a day of the week or a unit.
+// Weeks start on Monday
+func (p naturalParse) relative(tokens []string) (Date, bool, error) {
+	if len(tokens) != 2 {
+		return Date{}, false, nil
+	}
+	key := p.words.Keywords[tokens[0]]
+	if key != KeyThis && key != KeyNext && key != KeyLast {
+		return Date{}, false, nil
+	}
+	if unit, ok := p.words.Units[tokens[1]]; ok {
+		n := map[Keyword]int{KeyThis: 0, KeyNext: 1, KeyLast: -1}[key]
+		date, err := p.ref.add(unit, n)
+		return date, true, err
+	}
+	day, ok := p.words.Weekdays[tokens[1]]
+	if !ok {
+		return Date{}, false, nil
+	}
+	// Days from ref to that day in ref's week
+	fromMonday := func(d time.Weekday) int { return (int(d) + 6) % 7 }
+	inWeek := fromMonday(day) - fromMonday(p.ref.Weekday())
+	switch key {
+	case KeyThis:
+		date, err := p.ref.AddDays(inWeek)
+		return date, true, err
+	case KeyLast:
+		ago := (int(p.ref.Weekday()) - int(day) + 7) % 7
+		if ago == 0 {
+			ago = 7
+		}
+		date, err := p.ref.AddDays(-ago)
+		return date, true, err
+	}
+	// Next friday said on a Wednesday could mean the
+	// one in two days or the one in the week after
+	if inWeek > 0 {
+		return p.ambiguous(inWeek, inWeek+7)
+	}
+	date, err := p.ref.AddDays(inWeek + 7)
+	return date, true, err
+}
+
+// in 3 weeks, 3 weeks ago
+func (p naturalParse) offset(tokens []string) (Date, bool, error) {
+	if len(tokens) != 3 {
+		return Date{}, false, nil
+	}
+	sign := 0
+	switch {
+	case p.words.Keywords[tokens[0]] == KeyIn:
+		sign, tokens = 1, tokens[1:]
+	case p.words.Keywords[tokens[2]] == KeyAgo:
+		sign, tokens = -1, tokens[:2]
+	case p.words.Keywords[tokens[0]] == KeyAgo:
+		// Some languages put ago first, like hace in Spanish
+		sign, tokens = -1, tokens[1:]
+	default:
+		return Date{}, false, nil
+	}
+	n, ok := p.number(tokens[0])
+	unit, isUnit := p.words.Units[tokens[1]]
+	if !ok || !isUnit {
+		return Date{}, false, nil
+	}
+	date, err := p.ref.add(unit, sign*n)
+	return date, true, err
+}
+
+// Dec 21, 21 Dec, Dec 21 1974, 21 Dec 1974
+func (p naturalParse) monthDay(tokens []string) (Date, bool, error) {
+	if len(tokens) != 2 && len(tokens) != 3 {
+		return Date{}, false, nil
+	}
+	month, ok := p.words.Months[tokens[0]]
+	dayText := tokens[1]
+	if !ok {
+		month, ok = p.words.Months[tokens[1]]
+		dayText = tokens[0]
+	}
+	if !ok {
+		return Date{}, false, nil
+	}
+	day, ok := readDayNumber(dayText)
+	if !ok {
+		return Date{}, false, nil
+	}
+	if len(tokens) == 3 {
+		year, err := strconv.Atoi(tokens[2])
+		if err != nil {
+			return Date{}, false, nil
+		}
+		date, err := p.ref.withDate(year, month, day)
+		return date, true, err
+	}
+	date, err := p.nextDay(month, day)
+	return date, true, err
+}
+
+// Read a day like 21 or 21st. The suffix must be the one
+// that goes with the number, so 21th and 3nd are not
+// days
+func readDayNumber(text string) (int, bool) {
+	digits := strings.TrimRight(text, "abcdefghijklmnopqrstuvwxyz")
+	day, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false
+	}
+	suffix := text[len(digits):]
+	return day, suffix == "" || suffix == ordinalSuffix(day)
+}
+
+// Return the English suffix for a number, st for 1 and
+// 21 but th for 11
+func ordinalSuffix(n int) string {
+	if n%100 >= 11 && n%100 <= 13 {
+		return "th"
+	}
+	switch n % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
+
+// 12/21 or 12/21/1974, read in the words' order
+func (p naturalParse) numeric(tokens []string) (Date, bool, error) {
+	if len(tokens) != 1 {
+		return Date{}, false, nil
+	}
+	parts := strings.Split(tokens[0], "/")
+	if len(parts) != 2 && len(parts) != 3 {
+		return Date{}, false, nil
+	}
+	var numbers []int
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return Date{}, false, nil
+		}
+		numbers = append(numbers, n)
+	}
+	read := func(month, day int) (Date, error) {
+		if len(numbers) == 3 {
+			return p.ref.withDate(numbers[2], month, day)
+		}
+		return p.nextDay(month, day)
+	}
+	monthFirst, errMonth := read(numbers[0], numbers[1])
+	dayFirst, errDay := read(numbers[1], numbers[0])
+	switch {
+	case p.words.Order == MonthFirst:
+		return monthFirst, true, errMonth
+	case p.words.Order == DayFirst:
+		return dayFirst, true, errDay
+	case errMonth != nil:
+		return dayFirst, true, errDay
+	case errDay != nil || monthFirst.Equal(dayFirst):
+		return monthFirst, true, nil
+	}
+	return Date{}, true, &AmbiguousError{p.input, []Date{monthFirst, dayFirst}}
+}
+
+// Read a number written in digits or as a word
+func (p naturalParse) number(token string) (int, bool) {
+	if n, ok := p.words.Numbers[token]; ok {
+		return n, true
+	}
+	n, err := strconv.Atoi(token)
+	return n, err == nil && n >= 0
+}
+
+// The next time a day and month come, counting ref.
+// 29 February waits for a leap year
+func (p naturalParse) nextDay(month, day int) (Date, error) {
+	if month < 1 || month > 12 {
+		return Date{}, &DateError{FieldMonth, month, "must be from 1 to 12"}
+	}
+	year := p.ref.year
+	if month < p.ref.month || (month == p.ref.month && day < p.ref.day) {
+		year++
+	}
+	for day == 29 && month == 2 && !isLeap(year) {
+		year++
+	}
+	return p.ref.withDate(year, month, day)
+}
+
+// Report that the expression could be either of two
+// days from ref
+func (p naturalParse) ambiguous(a, b int) (Date, bool, error) {
+	first, err := p.ref.AddDays(a)
+	if err != nil {
+		return Date{}, true, err
+	}
+	second, err := p.ref.AddDays(b)
+	if err != nil {
+		return first, true, nil
+	}
+	return Date{}, true, &AmbiguousError{p.input, []Date{first, second}}
+}
+
+// Move a date by n units
+func (d Date) add(unit Unit, n int) (Date, error) {
+	switch unit {
+	case UnitWeek:
+		return d.AddDays(7 * n)
+	case UnitMonth:
+		return d.AddMonths(n)
+	case UnitYear:
+		return d.AddYears(n)
+	}
+	return d.AddDays(n)
+}
@@ -0,0 +1,159 @@
+package stuff
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseNatural(t *testing.T) {
+	// A Wednesday
+	ref := mustParse(t, "2024-06-12")[0]
+	tests := []struct{ input, want string }{
+		{"today", "2024-06-12"},
+		{"Tomorrow", "2024-06-13"},
+		{"yesterday", "2024-06-11"},
+		{"friday", "2024-06-14"},
+		{"on Monday", "2024-06-17"},
+		{"this friday", "2024-06-14"},
+		{"this monday", "2024-06-10"},
+		{"next monday", "2024-06-17"},
+		{"last friday", "2024-06-07"},
+		{"last wed", "2024-06-05"},
+		{"next week", "2024-06-19"},
+		{"last month", "2024-05-12"},
+		{"next year", "2025-06-12"},
+		{"in 3 weeks", "2024-07-03"},
+		{"in a day", "2024-06-13"},
+		{"in two months", "2024-08-12"},
+		{"10 days ago", "2024-06-02"},
+		{"Dec 21", "2024-12-21"},
+		{"21st of December", "2024-12-21"},
+		{"Dec 2nd", "2024-12-02"},
+		{"3rd Dec", "2024-12-03"},
+		{"Dec 11th", "2024-12-11"},
+		{"Dec 12th", "2024-12-12"},
+		{"June 12", "2024-06-12"},
+		{"Jan 5", "2025-01-05"},
+		{"Feb 29", "2028-02-29"},
+		{"December 21, 1974", "1974-12-21"},
+		{"12/21", "2024-12-21"},
+		{"12/21/1974", "1974-12-21"},
+		{"1974-12-21", "1974-12-21"},
+	}
+	for _, test := range tests {
+		got, err := ParseNatural(test.input, ref)
+		if err != nil {
+			t.Errorf("%q: %v", test.input, err)
+		} else if got.String() != test.want {
+			t.Errorf("%q = %v, want %s", test.input, got, test.want)
+		}
+	}
+}
+
+func TestParseNaturalAmbiguous(t *testing.T) {
+	wednesday := mustParse(t, "2024-06-12")[0]
+	either := English
+	either.Order = EitherOrder
+	tests := []struct {
+		input string
+		ref   Date
+		words Words
+		want  []string
+	}{
+		{"next friday", wednesday, English, []string{"2024-06-14", "2024-06-21"}},
+		{"wednesday", wednesday, English, []string{"2024-06-12", "2024-06-19"}},
+		{"3/4/2020", wednesday, either, []string{"2020-03-04", "2020-04-03"}},
+	}
+
+	for _, test := range tests {
+		_, err := test.words.Parse(test.input, test.ref)
+		var ambiguous *AmbiguousError
+		if !errors.As(err, &ambiguous) {
+			t.Errorf("%q: got %v, want an AmbiguousError", test.input, err)
+			continue
+		}
+		if got := isoStrings(ambiguous.Choices); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q could be %v, want %v", test.input, got, test.want)
+		}
+	}
+
+	// Only one reading is a real date
+	got, err := either.Parse("13/4/2020", wednesday)
+	if err != nil || got.String() != "2020-04-13" {
+		t.Errorf("13/4/2020 = %v, %v", got, err)
+	}
+}
+
+func TestParseNaturalErrors(t *testing.T) {
+	ref := mustParse(t, "2024-06-12")[0]
+	for _, input := range []string{"", "soon", "next blursday", "in many days", "Feb 30", "13/13",
+		"21th of December", "Dec 1s", "3nd Dec", "Dec 11st", "Dec 21stt", "Dec st"} {
+		if got, err := ParseNatural(input, ref); err == nil {
+			t.Errorf("%q = %v, want an error", input, got)
+		}
+	}
+	// The earliest year of ref's policy still holds
+	birthday, _ := Birthdate().NewDate(1974, 12, 21)
+	if _, err := ParseNatural("200 years ago", birthday); err == nil {
+		t.Error("went before 1875 with Birthdate")
+	}
+}
+
+// Days after today work whatever ref's policy is
+func TestParseNaturalFromToday(t *testing.T) {
+	today := Today(nil)
+	now := time.Now()
+	birthdateToday, err := NewDate(now.Year(), int(now.Month()), now.Day())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range []Date{today, birthdateToday} {
+		tomorrow, err := ParseNatural("tomorrow", ref)
+		if err != nil || tomorrow.DaysBetween(ref) != -1 {
+			t.Errorf("tomorrow from %v = %v, %v", ref, tomorrow, err)
+		}
+		friday, err := ParseNatural("next friday", ref)
+		var ambiguous *AmbiguousError
+		if errors.As(err, &ambiguous) {
+			friday, err = ambiguous.Choices[0], nil
+		}
+		if err != nil || friday.Weekday() != time.Friday || !friday.After(ref) {
+			t.Errorf("next friday from %v = %v, %v", ref, friday, err)
+		}
+	}
+}
+
+func TestParseNaturalSpanish(t *testing.T) {
+	spanish := Words{
+		Keywords: map[string]Keyword{
+			"hoy": KeyToday, "mañana": KeyTomorrow, "ayer": KeyYesterday,
+			"este": KeyThis, "próximo": KeyNext, "pasado": KeyLast,
+			"en": KeyIn, "hace": KeyAgo, "el": KeyIgnore, "de": KeyIgnore,
+		},
+		Weekdays: map[string]time.Weekday{"lunes": time.Monday, "viernes": time.Friday},
+		Months:   map[string]int{"diciembre": 12},
+		Units:    map[string]Unit{"día": UnitDay, "días": UnitDay, "semanas": UnitWeek},
+		Numbers:  map[string]int{"un": 1, "dos": 2, "tres": 3},
+		Order:    DayFirst,
+	}
+	ref := mustParse(t, "2024-06-12")[0]
+	tests := []struct{ input, want string }{
+		{"mañana", "2024-06-13"},
+		{"el viernes", "2024-06-14"},
+		{"el próximo lunes", "2024-06-17"},
+		{"en tres semanas", "2024-07-03"},
+		{"hace dos días", "2024-06-10"},
+		{"21 de diciembre", "2024-12-21"},
+		{"3/4/2020", "2020-04-03"},
+	}
+	for _, test := range tests {
+		got, err := spanish.Parse(test.input, ref)
+		if err != nil {
+			t.Errorf("%q: %v", test.input, err)
+		} else if got.String() != test.want {
+			t.Errorf("%q = %v, want %s", test.input, got, test.want)
+		}
+	}
+}
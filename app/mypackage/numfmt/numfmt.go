@@ -0,0 +1,180 @@
+// Package numfmt formats numbers for people to read:
+// with thousands separators, in compact form like 1.2K,
+// as ordinals, Roman numerals and English words
+package numfmt
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Locale is how a country writes numbers
+type Locale struct {
+	// Put between groups of three digits
+	Group string
+	// Put between the whole number and the fraction
+	Decimal string
+}
+
+// Some common locales
+var (
+	English = Locale{Group: ",", Decimal: "."}
+	German  = Locale{Group: ".", Decimal: ","}
+	// French uses a narrow no-break space
+	French = Locale{Group: " ", Decimal: ","}
+	Swiss  = Locale{Group: "'", Decimal: "."}
+)
+
+// Format a whole number like 1,234,567
+func (l Locale) Int(n int64) string {
+	digits := strconv.FormatUint(abs(n), 10)
+	if n < 0 {
+		return "-" + l.group(digits)
+	}
+	return l.group(digits)
+}
+
+// Format a number with a fixed number of decimal
+// places like 1,234.50
+func (l Locale) Float(f float64, decimals int) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	text := strconv.FormatFloat(math.Abs(f), 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(text, ".")
+	text = l.group(whole)
+	if fraction != "" {
+		text += l.Decimal + fraction
+	}
+	// Rounding can turn -0.001 into 0.00, which has no sign
+	if f < 0 && strings.Trim(whole+fraction, "0") != "" {
+		text = "-" + text
+	}
+	return text
+}
+
+// Read a number written in this locale, the reverse of
+// Int and Float. Groups must have three digits, so
+// 1,234.5 isn't read as German
+func (l Locale) Parse(s string) (float64, error) {
+	fail := func() (float64, error) {
+		return 0, fmt.Errorf("numfmt: %q is not a number in this locale", s)
+	}
+	whole, fraction, hasFraction := strings.Cut(strings.TrimSpace(s), l.Decimal)
+	if l.Group != "" && strings.Contains(whole, l.Group) {
+		groups := strings.Split(whole, l.Group)
+		for _, group := range groups[1:] {
+			if len(group) != 3 {
+				return fail()
+			}
+		}
+		whole = strings.Join(groups, "")
+	}
+	text := whole
+	if hasFraction {
+		text += "." + fraction
+	}
+	// Only digits and a sign can be left
+	if strings.Trim(text, "+-.0123456789") != "" || strings.Count(text, ".") > 1 {
+		return fail()
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return fail()
+	}
+	return f, nil
+}
+
+// Put the group separator between every three digits
+// counting from the right
+func (l Locale) group(digits string) string {
+	if len(digits) <= 3 || l.Group == "" {
+		return digits
+	}
+	var out strings.Builder
+	first := len(digits) % 3
+	if first == 0 {
+		first = 3
+	}
+	out.WriteString(digits[:first])
+	for i := first; i < len(digits); i += 3 {
+		out.WriteString(l.Group)
+		out.WriteString(digits[i : i+3])
+	}
+	return out.String()
+}
+
+// Return the size of n. This works for the smallest
+// int64 too, which has no positive int64
+func abs(n int64) uint64 {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1
+	}
+	return uint64(n)
+}
+
+// Suffixes for thousands, millions, billions and
+// trillions
+var compactUnits = []string{"", "K", "M", "B", "T"}
+
+// Format a number in a few characters, like 950, 1.2K
+// or 3.4M, with at most one decimal place
+func (l Locale) Compact(f float64) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	unit := 0
+	value := f
+	for math.Abs(value) >= 1000 && unit < len(compactUnits)-1 {
+		value /= 1000
+		unit++
+	}
+	// 999,950 rounds to 1000.0K, so it should be 1M
+	if math.Abs(math.Round(value*10)/10) >= 1000 && unit < len(compactUnits)-1 {
+		value /= 1000
+		unit++
+	}
+	text := l.Float(value, 1)
+	text = strings.TrimSuffix(text, l.Decimal+"0")
+	return text + compactUnits[unit]
+}
+
+// Read a compact number like 1.2K back. The result is
+// only as exact as the compact form
+func (l Locale) ParseCompact(s string) (float64, error) {
+	text := strings.TrimSpace(s)
+	scale := 1.0
+	for i := len(compactUnits) - 1; i > 0; i-- {
+		if strings.HasSuffix(strings.ToUpper(text), compactUnits[i]) {
+			text = text[:len(text)-len(compactUnits[i])]
+			scale = math.Pow(1000, float64(i))
+			break
+		}
+	}
+	f, err := l.Parse(text)
+	if err != nil {
+		return 0, err
+	}
+	return f * scale, nil
+}
+
+// Format a number as an English ordinal like 1st, 2nd,
+// 3rd, 11th or 21st
+func Ordinal(n int64) string {
+	suffix := "th"
+	switch abs(n) % 100 {
+	case 11, 12, 13:
+	default:
+		switch abs(n) % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.FormatInt(n, 10) + suffix
+}
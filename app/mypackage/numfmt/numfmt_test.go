@@ -0,0 +1,196 @@
+package numfmt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLocaleInt(t *testing.T) {
+	tests := []struct {
+		locale Locale
+		n      int64
+		want   string
+	}{
+		{English, 0, "0"},
+		{English, 999, "999"},
+		{English, 1000, "1,000"},
+		{English, -1234567, "-1,234,567"},
+		{German, 1234567, "1.234.567"},
+		{French, 1234567, "1 234 567"},
+		{Swiss, 1234567, "1'234'567"},
+		{English, math.MinInt64, "-9,223,372,036,854,775,808"},
+		{Locale{Decimal: "."}, 1234567, "1234567"},
+	}
+	for _, test := range tests {
+		if got := test.locale.Int(test.n); got != test.want {
+			t.Errorf("Int(%d) = %q, want %q", test.n, got, test.want)
+		}
+	}
+}
+
+func TestLocaleFloat(t *testing.T) {
+	tests := []struct {
+		locale   Locale
+		f        float64
+		decimals int
+		want     string
+	}{
+		{English, 1234.5, 2, "1,234.50"},
+		{German, 1234.5, 2, "1.234,50"},
+		{English, -0.004, 2, "0.00"},
+		{English, -1234567.891, 1, "-1,234,567.9"},
+		{English, 999.999, 2, "1,000.00"},
+		{English, 42, 0, "42"},
+	}
+	for _, test := range tests {
+		if got := test.locale.Float(test.f, test.decimals); got != test.want {
+			t.Errorf("Float(%v, %d) = %q, want %q", test.f, test.decimals, got, test.want)
+		}
+	}
+}
+
+func TestLocaleRoundTrip(t *testing.T) {
+	values := []float64{0, 1, -1, 12.25, 999.5, 1000, 1234567.75, -98765.5, 1e15}
+	for _, locale := range []Locale{English, German, French, Swiss} {
+		for _, f := range values {
+			text := locale.Float(f, 2)
+			back, err := locale.Parse(text)
+			if err != nil || back != f {
+				t.Errorf("%+q: %v became %q then %v, %v", locale, f, text, back, err)
+			}
+		}
+	}
+	if _, err := German.Parse("1,234.5"); err == nil {
+		t.Error("German read an English number")
+	}
+	if _, err := English.Parse("lots"); err == nil {
+		t.Error("read a word as a number")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	tests := []struct {
+		locale Locale
+		f      float64
+		want   string
+	}{
+		{English, 0, "0"},
+		{English, 950, "950"},
+		{English, 12.26, "12.3"},
+		{English, 1000, "1K"},
+		{English, 1234, "1.2K"},
+		{German, 1234, "1,2K"},
+		{English, 999949, "999.9K"},
+		{English, 999950, "1M"},
+		{English, -3400000, "-3.4M"},
+		{English, 7.2e9, "7.2B"},
+		{English, 5e12, "5T"},
+		{English, 5e15, "5,000T"},
+	}
+	for _, test := range tests {
+		got := test.locale.Compact(test.f)
+		if got != test.want {
+			t.Errorf("Compact(%v) = %q, want %q", test.f, got, test.want)
+		}
+		// Reading it back gives the number to within the
+		// one decimal place shown
+		back, err := test.locale.ParseCompact(got)
+		if diff := math.Abs(back - test.f); err != nil || (diff > 0.05 && diff > 0.05*math.Abs(test.f)) {
+			t.Errorf("ParseCompact(%q) = %v, %v, want about %v", got, back, err, test.f)
+		}
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	tests := map[int64]string{
+		0: "0th", 1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th",
+		13: "13th", 21: "21st", 22: "22nd", 101: "101st", 111: "111th", 112: "112th",
+		1003: "1003rd", -1: "-1st", -11: "-11th",
+	}
+	for n, want := range tests {
+		if got := Ordinal(n); got != want {
+			t.Errorf("Ordinal(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestRoman(t *testing.T) {
+	tests := map[int]string{
+		1: "I", 4: "IV", 9: "IX", 14: "XIV", 40: "XL", 90: "XC", 400: "CD",
+		1974: "MCMLXXIV", 2024: "MMXXIV", 3999: "MMMCMXCIX",
+	}
+	for n, want := range tests {
+		if got, err := Roman(n); err != nil || got != want {
+			t.Errorf("Roman(%d) = %q, %v, want %q", n, got, err, want)
+		}
+	}
+	// Every number there is should come back the same
+	for n := 1; n <= MaxRoman; n++ {
+		text, err := Roman(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if back, err := ParseRoman(text); err != nil || back != n {
+			t.Fatalf("%d became %q then %d, %v", n, text, back, err)
+		}
+	}
+	if got, err := ParseRoman("mcmlxxiv"); err != nil || got != 1974 {
+		t.Errorf("ParseRoman(mcmlxxiv) = %d, %v", got, err)
+	}
+	for _, bad := range []string{"", "IIII", "IC", "VX", "MMMM", "XIZ", "IVI"} {
+		if n, err := ParseRoman(bad); err == nil {
+			t.Errorf("ParseRoman(%q) = %d, want an error", bad, n)
+		}
+	}
+	for _, bad := range []int{0, -5, 4000} {
+		if _, err := Roman(bad); err == nil {
+			t.Errorf("Roman(%d) succeeded", bad)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	tests := map[int64]string{
+		0:             "zero",
+		7:             "seven",
+		15:            "fifteen",
+		40:            "forty",
+		42:            "forty-two",
+		100:           "one hundred",
+		105:           "one hundred five",
+		1974:          "one thousand nine hundred seventy-four",
+		1000000:       "one million",
+		-2000017:      "minus two million seventeen",
+		1001001001:    "one billion one million one thousand one",
+		math.MaxInt64: "nine quintillion two hundred twenty-three quadrillion three hundred seventy-two trillion thirty-six billion eight hundred fifty-four million seven hundred seventy-five thousand eight hundred seven",
+	}
+	for n, want := range tests {
+		if got := Words(n); got != want {
+			t.Errorf("Words(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestWordsRoundTrip(t *testing.T) {
+	values := []int64{0, 1, -1, 19, 20, 21, 99, 100, 101, 999, 1000, 1001, 12345,
+		-999999, 1000000, 123456789, math.MaxInt64, math.MinInt64}
+	for n := int64(0); n < 2000; n++ {
+		values = append(values, n)
+	}
+	for _, n := range values {
+		text := Words(n)
+		back, err := ParseWords(text)
+		if err != nil || back != n {
+			t.Errorf("%d became %q then %d, %v", n, text, back, err)
+		}
+	}
+	if got, err := ParseWords("One Hundred and Five"); err != nil || got != 105 {
+		t.Errorf("ParseWords(One Hundred and Five) = %d, %v", got, err)
+	}
+	for _, bad := range []string{"", "five twenty", "one thousand million", "twenty twenty",
+		"a hundred", "minus zero", "nine quintillion three hundred quadrillion", "ten hundred"} {
+		if n, err := ParseWords(bad); err == nil {
+			t.Errorf("ParseWords(%q) = %d, want an error", bad, n)
+		}
+	}
+}
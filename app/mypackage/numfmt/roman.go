@@ -0,0 +1,56 @@
+package numfmt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Roman numerals from biggest to smallest, with the
+// pairs like CM that subtract
+var romanNumerals = []struct {
+	value  int
+	symbol string
+}{
+	{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"},
+	{100, "C"}, {90, "XC"}, {50, "L"}, {40, "XL"},
+	{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+}
+
+// The largest number Roman numerals can write without
+// a bar over them
+const MaxRoman = 3999
+
+// Write a number from 1 to 3999 in Roman numerals
+func Roman(n int) (string, error) {
+	if n < 1 || n > MaxRoman {
+		return "", fmt.Errorf("numfmt: %d can't be written in Roman numerals, it must be from 1 to %d", n, MaxRoman)
+	}
+	var out strings.Builder
+	for _, numeral := range romanNumerals {
+		for n >= numeral.value {
+			out.WriteString(numeral.symbol)
+			n -= numeral.value
+		}
+	}
+	return out.String(), nil
+}
+
+// Read Roman numerals in either case. Only the usual
+// form is accepted, so IIII and IC are errors
+func ParseRoman(s string) (int, error) {
+	text := strings.ToUpper(s)
+	n := 0
+	rest := text
+	for _, numeral := range romanNumerals {
+		for strings.HasPrefix(rest, numeral.symbol) {
+			n += numeral.value
+			rest = rest[len(numeral.symbol):]
+		}
+	}
+	// Writing the number back shows if it was the usual
+	// form
+	if back, err := Roman(n); err != nil || rest != "" || back != text {
+		return 0, fmt.Errorf("numfmt: %q is not a Roman numeral", s)
+	}
+	return n, nil
+}
@@ -0,0 +1,116 @@
+package numfmt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// English names of numbers
+var (
+	smallWords = []string{"zero", "one", "two", "three", "four", "five", "six",
+		"seven", "eight", "nine", "ten", "eleven", "twelve", "thirteen", "fourteen",
+		"fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+	tensWords = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty",
+		"seventy", "eighty", "ninety"}
+	scaleWords = []string{"", "thousand", "million", "billion", "trillion",
+		"quadrillion", "quintillion"}
+)
+
+// Spell a number in English like
+// one hundred twenty-three thousand four hundred five
+func Words(n int64) string {
+	if n == 0 {
+		return smallWords[0]
+	}
+	var groups []string
+	size := abs(n)
+	for scale := 0; size > 0; scale++ {
+		if group := size % 1000; group > 0 {
+			words := hundredsWords(int(group))
+			if scaleWords[scale] != "" {
+				words += " " + scaleWords[scale]
+			}
+			groups = append([]string{words}, groups...)
+		}
+		size /= 1000
+	}
+	text := strings.Join(groups, " ")
+	if n < 0 {
+		return "minus " + text
+	}
+	return text
+}
+
+// Spell a number from 1 to 999
+func hundredsWords(n int) string {
+	var words []string
+	if n >= 100 {
+		words = append(words, smallWords[n/100], "hundred")
+		n %= 100
+	}
+	switch {
+	case n >= 20 && n%10 != 0:
+		words = append(words, tensWords[n/10]+"-"+smallWords[n%10])
+	case n >= 20:
+		words = append(words, tensWords[n/10])
+	case n > 0:
+		words = append(words, smallWords[n])
+	}
+	return strings.Join(words, " ")
+}
+
+// Read a number spelled in English, the reverse of
+// Words. Case, extra spaces and "and" are allowed, so
+// "One hundred and five" works, but the words have to
+// be in the order Words puts them
+func ParseWords(s string) (int64, error) {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(strings.ReplaceAll(s, "-", " "))) {
+		if word != "and" {
+			words = append(words, word)
+		}
+	}
+	negative := len(words) > 0 && words[0] == "minus"
+
+	var total, group uint64
+	for _, word := range words {
+		switch {
+		case word == "minus":
+		case indexOf(smallWords, word) >= 0:
+			group += uint64(indexOf(smallWords, word))
+		case indexOf(tensWords[2:], word) >= 0:
+			group += uint64(indexOf(tensWords, word)) * 10
+		case word == "hundred":
+			group *= 100
+		case indexOf(scaleWords[1:], word) >= 0:
+			for i := 0; i < indexOf(scaleWords, word); i++ {
+				group *= 1000
+			}
+			total += group
+			group = 0
+		default:
+			return 0, fmt.Errorf("numfmt: %q has %q, which isn't a number word", s, word)
+		}
+	}
+	total += group
+	n := int64(total)
+	if negative {
+		n = -n
+	}
+	// Spelling the number again catches words in the
+	// wrong order and numbers too big for an int64
+	if strings.Join(words, " ") != strings.ReplaceAll(Words(n), "-", " ") {
+		return 0, fmt.Errorf("numfmt: %q is not a number in words", s)
+	}
+	return n, nil
+}
+
+// Return the position of s in list, or -1
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,233 @@
+package stuff
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Policy decides which dates are allowed. A zero Min or
+// Max means there is no bound on that side, and
+// AllowFuture lets dates after today through
+type Policy struct {
+	Min         Date
+	Max         Date
+	AllowFuture bool
+	// Clock tells the time for working out today. It is
+	// time.Now if nil, tests can set a fixed time. A date
+	// made with the policy doesn't keep it, see
+	// SetDefaultPolicy
+	Clock func() time.Time
+}
+
+// Dates any policy can hold, so they always format
+// with four digit years
+const (
+	firstYear = 1
+	lastYear  = 9999
+)
+
+// The presets are functions that return a new copy, so
+// changing one, like setting its Clock in a test,
+// can't change it for anyone else
+
+// Birthdate allows days from 1875 up to today
+func Birthdate() Policy {
+	return Policy{Min: Date{day: 1, month: 1, year: minYear}}
+}
+
+// Scheduling allows due dates in the future as well as
+// past ones
+func Scheduling() Policy {
+	return Policy{Min: Date{day: 1, month: 1, year: minYear}, AllowFuture: true}
+}
+
+// Unbounded allows any day from year 1 to 9999, past or
+// future. It is for plain calendar dates, like ones
+// converted from a time.Time
+func Unbounded() Policy {
+	return Policy{AllowFuture: true}
+}
+
+// The policy used by NewDate, Parse and dates created
+// without a policy. It starts as Birthdate, change it
+// with SetDefaultPolicy
+var defaultPolicy = struct {
+	sync.RWMutex
+	policy Policy
+}{policy: Birthdate()}
+
+// SetDefaultPolicy changes the policy used by NewDate,
+// Parse and dates created without a policy, including
+// ones made before the call. A copy of p is kept, so
+// changing p afterwards changes nothing. Its Clock also
+// tells the time for dates checked later, see Policy
+func SetDefaultPolicy(p Policy) {
+	defaultPolicy.Lock()
+	defer defaultPolicy.Unlock()
+	defaultPolicy.policy = p
+}
+
+// DefaultPolicy returns a copy of the package default
+func DefaultPolicy() Policy {
+	defaultPolicy.RLock()
+	defer defaultPolicy.RUnlock()
+	return defaultPolicy.policy
+}
+
+// Return the current time from the policy's clock
+func (p Policy) now() time.Time {
+	if p.Clock == nil {
+		return time.Now()
+	}
+	return p.Clock()
+}
+
+// Return today's date in the local time zone by the
+// policy's clock
+func (p Policy) Today() Date {
+	return p.TodayIn(time.Local)
+}
+
+// The latest date allowed, or a zero Date for none
+func (p Policy) latest() Date {
+	latest := p.Max
+	if !p.AllowFuture {
+		today := p.Today()
+		if latest.IsZero() || today.Before(latest) {
+			latest = today
+		}
+	}
+	return latest
+}
+
+// Check a whole date. The year and month are checked
+// first so the day can be checked against the
+// length of that month, then the date against the
+// bounds
+func (p Policy) validate(y, m, d int) error {
+	err := p.validateYear(y)
+	if err != nil {
+		return err
+	}
+	if (m < 1) || (m > 12) {
+		return &DateError{FieldMonth, m, "must be from 1 to 12"}
+	}
+	if (d < 1) || (d > daysIn(y, m)) {
+		return &DateError{FieldDay, d, fmt.Sprintf("%s %d has %d days",
+			time.Month(m), y, daysIn(y, m))}
+	}
+	date := Date{day: d, month: m, year: y}
+	if !p.Min.IsZero() && date.Before(p.Min) {
+		return outOfBounds(date, p.Min, "on or after")
+	}
+	if latest := p.latest(); !latest.IsZero() && date.After(latest) {
+		return outOfBounds(date, latest, "on or before")
+	}
+	return nil
+}
+
+// Check a year is inside the years the policy allows
+func (p Policy) validateYear(y int) error {
+	from, to := firstYear, lastYear
+	if !p.Min.IsZero() {
+		from = p.Min.year
+	}
+	if latest := p.latest(); !latest.IsZero() {
+		to = latest.year
+	}
+	if (y < from) || (y > to) {
+		return &DateError{FieldYear, y, fmt.Sprintf("must be from %d to %d", from, to)}
+	}
+	return nil
+}
+
+// Blame the first field where a date differs from
+// the bound it broke
+func outOfBounds(date, bound Date, side string) error {
+	reason := fmt.Sprintf("date must be %s %v", side, bound)
+	switch {
+	case date.year != bound.year:
+		return &DateError{FieldYear, date.year, reason}
+	case date.month != bound.month:
+		return &DateError{FieldMonth, date.month, reason}
+	}
+	return &DateError{FieldDay, date.day, reason}
+}
+
+// The bounds of a policy as a date keeps them. They are
+// plain values so dates can be compared with == and
+// used as map keys. The Clock isn't kept, so later
+// checks on a date tell the time by the clock of the
+// package default
+type dateRules struct {
+	// False for dates that follow the package default
+	set         bool
+	min, max    civilDay
+	allowFuture bool
+}
+
+// A day without a policy of its own
+type civilDay struct {
+	year, month, day int
+}
+
+// Return the bounds of the policy for a date to keep
+func (p Policy) kept() dateRules {
+	return dateRules{
+		set:         true,
+		min:         civilDay{p.Min.year, p.Min.month, p.Min.day},
+		max:         civilDay{p.Max.year, p.Max.month, p.Max.day},
+		allowFuture: p.AllowFuture,
+	}
+}
+
+// Create a date that keeps this policy, so later
+// changes to it are checked against the policy too
+func (p Policy) NewDate(y, m, d int) (Date, error) {
+	err := p.validate(y, m, d)
+	if err != nil {
+		return Date{}, err
+	}
+	return Date{day: d, month: m, year: y, policy: p.kept()}, nil
+}
+
+// Return the policy a date is checked against
+func (d Date) Policy() Policy {
+	return d.rules()
+}
+
+// The date's own policy with the default's clock, or
+// the package default
+func (d Date) rules() Policy {
+	policy := DefaultPolicy()
+	if !d.policy.set {
+		return policy
+	}
+	r := d.policy
+	return Policy{
+		Min:         Date{day: r.min.day, month: r.min.month, year: r.min.year},
+		Max:         Date{day: r.max.day, month: r.max.month, year: r.max.year},
+		AllowFuture: r.allowFuture,
+		Clock:       policy.Clock,
+	}
+}
+
+// Return d with a copy of its policy that also allows
+// the future, for days worked out ahead of it like
+// business days. The other bounds stay the same
+func (d Date) ahead() Date {
+	policy := d.rules()
+	policy.AllowFuture = true
+	d.policy = policy.kept()
+	return d
+}
+
+// Create a date with the same policy as d
+func (d Date) withDate(y, m, day int) (Date, error) {
+	err := d.rules().validate(y, m, day)
+	if err != nil {
+		return Date{}, err
+	}
+	return Date{day: day, month: m, year: y, policy: d.policy}, nil
+}
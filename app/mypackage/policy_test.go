@@ -0,0 +1,145 @@
+package stuff
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// A clock stopped at noon on 15 June 2024
+func fixedClock() time.Time {
+	return time.Date(2024, 6, 15, 12, 0, 0, 0, time.Local)
+}
+
+func TestPolicyBounds(t *testing.T) {
+	birthdate := Birthdate()
+	birthdate.Clock = fixedClock
+	scheduling := Scheduling()
+	scheduling.Clock = fixedClock
+	window := Policy{
+		Min:         Date{day: 1, month: 3, year: 2024},
+		Max:         Date{day: 30, month: 9, year: 2024},
+		AllowFuture: true,
+	}
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		y, m, d int
+		field   DateField // empty if the date is allowed
+	}{
+		{"birthdate today", birthdate, 2024, 6, 15, ""},
+		{"birthdate tomorrow", birthdate, 2024, 6, 16, FieldDay},
+		{"birthdate next month", birthdate, 2024, 7, 1, FieldMonth},
+		{"birthdate next year", birthdate, 2025, 1, 1, FieldYear},
+		{"birthdate too old", birthdate, 1874, 12, 31, FieldYear},
+		{"scheduling future", scheduling, 2030, 1, 1, ""},
+		{"scheduling too old", scheduling, 1800, 1, 1, FieldYear},
+		{"window first day", window, 2024, 3, 1, ""},
+		{"window last day", window, 2024, 9, 30, ""},
+		{"window before", window, 2024, 2, 29, FieldMonth},
+		{"window after", window, 2024, 10, 1, FieldMonth},
+		{"no bounds", Policy{AllowFuture: true}, 9999, 12, 31, ""},
+	}
+	for _, test := range tests {
+		_, err := test.policy.NewDate(test.y, test.m, test.d)
+		var dateErr *DateError
+		switch {
+		case test.field == "" && err != nil:
+			t.Errorf("%s: %v", test.name, err)
+		case test.field != "" && !errors.As(err, &dateErr):
+			t.Errorf("%s: got %v, want a DateError", test.name, err)
+		case test.field != "" && dateErr.Field != test.field:
+			t.Errorf("%s: blamed the %s, want the %s", test.name, dateErr.Field, test.field)
+		}
+	}
+}
+
+func TestDateKeepsPolicy(t *testing.T) {
+	scheduling := Scheduling()
+	scheduling.Clock = fixedClock
+	due, err := scheduling.NewDate(2024, 6, 15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Moving a due date into the future is fine
+	later, err := due.AddDays(30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := later.SetYear(2030); err != nil {
+		t.Error(err)
+	}
+	if err := later.UnmarshalText([]byte("2031-01-01")); err != nil {
+		t.Error(err)
+	}
+	if !later.Policy().AllowFuture {
+		t.Error("the date lost its policy")
+	}
+	// Equal ignores the policy
+	same, _ := NewDate(2024, 6, 15)
+	if !due.Equal(same) {
+		t.Error("dates with different policies should be Equal")
+	}
+}
+
+// Use a policy as the package default until the test
+// ends
+func testDefaultPolicy(t *testing.T, p Policy) {
+	t.Helper()
+	saved := DefaultPolicy()
+	t.Cleanup(func() { SetDefaultPolicy(saved) })
+	SetDefaultPolicy(p)
+}
+
+// NewDate and Parse use the package default, other
+// policies are passed in
+func TestDefaultPolicy(t *testing.T) {
+	birthdate := Birthdate()
+	birthdate.Clock = fixedClock
+	testDefaultPolicy(t, birthdate)
+	if _, err := NewDate(2024, 6, 16); err == nil {
+		t.Error("NewDate allowed tomorrow")
+	}
+	if _, err := Parse("2024-06-16"); err == nil {
+		t.Error("Parse allowed tomorrow")
+	}
+	if _, err := Scheduling().Parse("2099-01-01"); err != nil {
+		t.Error(err)
+	}
+	// Dates made before the change follow the new default
+	today, err := NewDate(2024, 6, 15)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduling := Scheduling()
+	scheduling.Clock = fixedClock
+	testDefaultPolicy(t, scheduling)
+	if err := today.SetYear(2099); err != nil {
+		t.Error(err)
+	}
+	// The default is a copy, and so are the presets
+	scheduling.AllowFuture = false
+	if !DefaultPolicy().AllowFuture || Birthdate().AllowFuture {
+		t.Error("changing a copy changed the default or a preset")
+	}
+}
+
+// Dates only hold values, so equal dates with the same
+// policy are == and work as map keys
+func TestDatesAreComparable(t *testing.T) {
+	scheduling := Scheduling()
+	scheduling.Clock = fixedClock
+	a, _ := scheduling.NewDate(2024, 6, 15)
+	b, _ := scheduling.NewDate(2024, 6, 15)
+	if a != b {
+		t.Errorf("%v != %v", a, b)
+	}
+	seen := map[Date]bool{a: true}
+	if !seen[b] {
+		t.Error("equal dates are different map keys")
+	}
+	if other, _ := Unbounded().NewDate(2024, 6, 15); other == a {
+		t.Error("dates with different policies are ==")
+	}
+}
@@ -0,0 +1,276 @@
+package stuff
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How often a rule repeats
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// Rule is the part of an RFC 5545 recurrence rule that
+// works on whole days, like
+// FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=1,-1;COUNT=6
+type Rule struct {
+	Freq Frequency
+	// Repeat every Interval days, weeks, months or
+	// years. Zero means 1
+	Interval int
+	// Only use these days of the week
+	ByDay []time.Weekday
+	// Only use these days of the month. Negative days
+	// count from the end, so -1 is the last day
+	ByMonthDay []int
+	// Stop after this many dates, or never if it is 0
+	Count int
+	// Stop after this day, or never if it is zero
+	Until Date
+}
+
+// The two letter names RFC 5545 gives the days
+var ruleDays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Expand needs to know when to stop
+var errEndless = errors.New("rule has no COUNT or UNTIL and no limit was given")
+
+// Read a rule like FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10.
+// An RRULE: prefix is allowed
+func ParseRule(s string) (Rule, error) {
+	var rule Rule
+	fail := func(format string, args ...interface{}) (Rule, error) {
+		return Rule{}, fmt.Errorf("rule %q: %s", s, fmt.Sprintf(format, args...))
+	}
+	for _, part := range strings.Split(strings.TrimPrefix(s, "RRULE:"), ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return fail("%q is not NAME=VALUE", part)
+		}
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(value))
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(value)
+			if err == nil && rule.Interval < 1 {
+				err = errors.New("must be at least 1")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(value)
+			if err == nil && rule.Count < 1 {
+				err = errors.New("must be at least 1")
+			}
+		case "UNTIL":
+			// Times after the date like T235959Z are ignored
+			day, _, _ := strings.Cut(value, "T")
+			rule.Until, err = Unbounded().ParseLayout("YYYYMMDD", day)
+		case "BYDAY":
+			for _, name := range strings.Split(value, ",") {
+				day := indexOf(ruleDays, strings.ToUpper(name))
+				if day < 0 {
+					return fail("BYDAY %q is not a day like MO", name)
+				}
+				rule.ByDay = append(rule.ByDay, time.Weekday(day))
+			}
+		case "BYMONTHDAY":
+			for _, text := range strings.Split(value, ",") {
+				day, err := strconv.Atoi(text)
+				if err != nil || day == 0 || day < -31 || day > 31 {
+					return fail("BYMONTHDAY %q must be from 1 to 31 or -31 to -1", text)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, day)
+			}
+		default:
+			return fail("%s is not supported", name)
+		}
+		if err != nil {
+			return fail("%s: %v", name, err)
+		}
+	}
+	switch rule.Freq {
+	case Daily, Weekly, Monthly, Yearly:
+	case "":
+		return fail("FREQ is missing")
+	default:
+		return fail("FREQ %s is not supported", rule.Freq)
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return fail("COUNT and UNTIL can't both be used")
+	}
+	return rule, nil
+}
+
+// Return the position of s in list, or -1
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// Write the rule back in RFC 5545 form
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		var days []string
+		for _, day := range r.ByDay {
+			days = append(days, ruleDays[day])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+strings.Join(IntArrToStrArr(r.ByMonthDay), ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.Format("YYYYMMDD"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Return the dates the rule gives starting from start,
+// which is like DTSTART. Only dates that match the
+// rule are returned. Expansion stops at COUNT, UNTIL or
+// limit, whichever comes first. A zero limit means
+// the rule must end by itself. The dates keep start's
+// policy, and expansion also stops without an error at
+// the last date it allows. A start with Birthdate gives
+// no dates after today, so use Scheduling or Unbounded
+// for rules that reach into the future
+func (r Rule) Expand(start, limit Date) ([]Date, error) {
+	end := r.Until
+	if !limit.IsZero() && (end.IsZero() || limit.Before(end)) {
+		end = limit
+	}
+	if end.IsZero() && r.Count == 0 {
+		return nil, errEndless
+	}
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	var dates []Date
+	for period := 0; ; period += interval {
+		for _, day := range r.candidates(start, period) {
+			if (!end.IsZero() && day.After(end)) || day.year > lastYear {
+				return dates, nil
+			}
+			if day.Before(start) || !r.matches(day) {
+				continue
+			}
+			// Stop at the edge of what the policy allows
+			date, err := start.withDate(day.year, day.month, day.day)
+			if err != nil {
+				return dates, nil
+			}
+			dates = append(dates, date)
+			if len(dates) == r.Count {
+				return dates, nil
+			}
+		}
+	}
+}
+
+// Check the BY parts that filter dates
+func (r Rule) matches(d Date) bool {
+	if len(r.ByDay) > 0 && !containsWeekday(r.ByDay, d.Weekday()) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 {
+		last := daysIn(d.year, d.month)
+		for _, day := range r.ByMonthDay {
+			if day == d.day || last+day+1 == d.day {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Check if a day of the week is in a list
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the days in order that might be in a period,
+// before the BY parts filter them. Period is how many
+// days, weeks, months or years after start it is
+func (r Rule) candidates(start Date, period int) []Date {
+	switch r.Freq {
+	case Daily:
+		return []Date{dateFromSerial(start.serial() + period)}
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return []Date{dateFromSerial(start.serial() + period*7)}
+		}
+		// Weeks start on Monday
+		monday := start.serial() - (int(start.Weekday())+6)%7 + period*7
+		return daysFromSerial(monday, 7)
+	case Monthly:
+		months := start.year*12 + start.month - 1 + period
+		y, m := months/12, months%12+1
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			return sameDay(y, m, start.day)
+		}
+		return monthDays(y, m)
+	}
+	y := start.year + period
+	if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+		return sameDay(y, start.month, start.day)
+	}
+	first := Date{day: 1, month: 1, year: y}.serial()
+	return daysFromSerial(first, Date{day: 1, month: 1, year: y + 1}.serial()-first)
+}
+
+// Return the day in a month if it exists, so the 31st
+// is skipped in 30 day months like RFC 5545 says
+func sameDay(y, m, d int) []Date {
+	if d > daysIn(y, m) {
+		return nil
+	}
+	return []Date{{day: d, month: m, year: y}}
+}
+
+// Return every day of a month
+func monthDays(y, m int) []Date {
+	return daysFromSerial(Date{day: 1, month: m, year: y}.serial(), daysIn(y, m))
+}
+
+// Return n days in a row starting from a serial day
+func daysFromSerial(first, n int) []Date {
+	days := make([]Date, n)
+	for i := range days {
+		days[i] = dateFromSerial(first + i)
+	}
+	return days
+}
+
+// Return the unchecked date for a serial day
+func dateFromSerial(z int) Date {
+	var d Date
+	d.year, d.month, d.day = fromSerial(z)
+	return d
+}
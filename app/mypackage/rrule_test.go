@@ -0,0 +1,106 @@
+package stuff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRuleExpand(t *testing.T) {
+	tests := []struct {
+		rule, start, limit string
+		want               []string
+	}{
+		{"FREQ=DAILY;INTERVAL=10;COUNT=3", "2024-02-25", "",
+			[]string{"2024-02-25", "2024-03-06", "2024-03-16"}},
+		// Start is a Wednesday, the Monday that week is skipped
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=4", "2024-01-03", "",
+			[]string{"2024-01-05", "2024-01-15", "2024-01-19", "2024-01-29"}},
+		{"FREQ=WEEKLY;UNTIL=20240124", "2024-01-03", "",
+			[]string{"2024-01-03", "2024-01-10", "2024-01-17", "2024-01-24"}},
+		// Months without a 31st are skipped
+		{"FREQ=MONTHLY;COUNT=4", "2024-01-31", "",
+			[]string{"2024-01-31", "2024-03-31", "2024-05-31", "2024-07-31"}},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=5", "2024-01-15", "",
+			[]string{"2024-01-31", "2024-02-01", "2024-02-29", "2024-03-01", "2024-03-31"}},
+		// Friday the 13th
+		{"FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13;COUNT=3", "2024-01-01", "",
+			[]string{"2024-09-13", "2024-12-13", "2025-06-13"}},
+		{"FREQ=YEARLY;COUNT=3", "2024-02-29", "",
+			[]string{"2024-02-29", "2028-02-29", "2032-02-29"}},
+		{"RRULE:FREQ=YEARLY;INTERVAL=2", "2020-07-04", "2026-12-31",
+			[]string{"2020-07-04", "2022-07-04", "2024-07-04", "2026-07-04"}},
+		// The limit stops a rule before its UNTIL
+		{"FREQ=DAILY;BYDAY=SA,SU;UNTIL=20241231T235959Z", "2024-03-01", "2024-03-10",
+			[]string{"2024-03-02", "2024-03-03", "2024-03-09", "2024-03-10"}},
+	}
+	for _, test := range tests {
+		rule, err := ParseRule(test.rule)
+		if err != nil {
+			t.Errorf("ParseRule(%q): %v", test.rule, err)
+			continue
+		}
+		start := mustParse(t, test.start)[0]
+		var limit Date
+		if test.limit != "" {
+			limit = mustParse(t, test.limit)[0]
+		}
+		dates, err := rule.Expand(start, limit)
+		if err != nil {
+			t.Errorf("%s: %v", test.rule, err)
+			continue
+		}
+		if got := isoStrings(dates); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s from %s = %v, want %v", test.rule, test.start, got, test.want)
+		}
+	}
+}
+
+func TestRuleStopsAtPolicy(t *testing.T) {
+	start, _ := Policy{
+		Max:         Date{day: 31, month: 12, year: 2024},
+		AllowFuture: true,
+	}.NewDate(2024, 12, 1)
+	rule, _ := ParseRule("FREQ=WEEKLY;COUNT=100")
+	dates, err := rule.Expand(start, Date{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dates) != 5 {
+		t.Errorf("got %v, want the 5 Sundays left in 2024", isoStrings(dates))
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	bad := []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;BYDAY=XX",
+		"FREQ=MONTHLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;COUNT=2;UNTIL=20240101",
+		"FREQ=DAILY;UNTIL=20240230",
+		"FREQ=DAILY;BYSETPOS=1",
+	}
+	for _, s := range bad {
+		if _, err := ParseRule(s); err == nil {
+			t.Errorf("ParseRule(%q) succeeded", s)
+		}
+	}
+	rule, _ := ParseRule("FREQ=DAILY")
+	if _, err := rule.Expand(mustParse(t, "2024-01-01")[0], Date{}); err != errEndless {
+		t.Errorf("endless rule expanded: %v", err)
+	}
+}
+
+func TestRuleString(t *testing.T) {
+	s := "FREQ=MONTHLY;INTERVAL=2;BYDAY=MO,FR;BYMONTHDAY=1,-1;UNTIL=20250101"
+	rule, err := ParseRule(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.String() != s {
+		t.Errorf("String = %q, want %q", rule.String(), s)
+	}
+}
@@ -0,0 +1,170 @@
+package stuff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Generics let one function work on slices of any
+// type. T and U stand for whatever types are used
+
+// Return a new slice with f applied to each value
+func Map[T, U any](s []T, f func(T) U) []U {
+	result := make([]U, 0, len(s))
+	for _, v := range s {
+		result = append(result, f(v))
+	}
+	return result
+}
+
+// Return the values that keep returns true for
+func Filter[T any](s []T, keep func(T) bool) []T {
+	var result []T
+	for _, v := range s {
+		if keep(v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Combine the values into one, starting with initial.
+// Summing is Reduce(s, 0, func(a, v int) int { return a + v })
+func Reduce[T, A any](s []T, initial A, f func(A, T) A) A {
+	result := initial
+	for _, v := range s {
+		result = f(result, v)
+	}
+	return result
+}
+
+// Put the values into groups by a key, keeping their
+// order inside each group
+func GroupBy[T any, K comparable](s []T, key func(T) K) map[K][]T {
+	groups := map[K][]T{}
+	for _, v := range s {
+		k := key(v)
+		groups[k] = append(groups[k], v)
+	}
+	return groups
+}
+
+// Split a slice into pieces of size values. The last
+// one can be shorter. It panics if size is less than 1
+func Chunk[T any](s []T, size int) [][]T {
+	if size < 1 {
+		panic(fmt.Sprintf("stuff.Chunk: size %d must be at least 1", size))
+	}
+	var chunks [][]T
+	for len(s) > size {
+		// Cap the chunk so appending to it can't
+		// overwrite the next one
+		chunks = append(chunks, s[:size:size])
+		s = s[size:]
+	}
+	if len(s) > 0 {
+		chunks = append(chunks, s)
+	}
+	return chunks
+}
+
+// Split the values into the ones pred returns true
+// for and the rest
+func Partition[T any](s []T, pred func(T) bool) (yes, no []T) {
+	for _, v := range s {
+		if pred(v) {
+			yes = append(yes, v)
+		} else {
+			no = append(no, v)
+		}
+	}
+	return yes, no
+}
+
+// Return the values without repeats, keeping the
+// first of each
+func Unique[T comparable](s []T) []T {
+	seen := map[T]bool{}
+	var result []T
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Pair holds one value from each slice given to Zip
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Pair up the values of two slices in order. Extra
+// values in the longer slice are left out
+func Zip[A, B any](a []A, b []B) []Pair[A, B] {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	pairs := make([]Pair[A, B], n)
+	for i := range pairs {
+		pairs[i] = Pair[A, B]{a[i], b[i]}
+	}
+	return pairs
+}
+
+// IndexError is a value in a slice that couldn't be
+// converted
+type IndexError struct {
+	Index int
+	Value string
+	Err   error
+}
+
+func (e *IndexError) Error() string {
+	return fmt.Sprintf("index %d (%q): %v", e.Index, e.Value, e.Err)
+}
+
+func (e *IndexError) Unwrap() error {
+	return e.Err
+}
+
+// IndexErrors is every value that couldn't be
+// converted, in order
+type IndexErrors []*IndexError
+
+func (errs IndexErrors) Error() string {
+	var lines []string
+	for _, err := range errs {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Convert every string with parse. Values that fail
+// are left as the zero value and all the failures are
+// returned together as IndexErrors
+func ParseAll[T any](strArr []string, parse func(string) (T, error)) ([]T, error) {
+	result := make([]T, len(strArr))
+	var errs IndexErrors
+	for i, s := range strArr {
+		v, err := parse(s)
+		if err != nil {
+			errs = append(errs, &IndexError{Index: i, Value: s, Err: err})
+			continue
+		}
+		result[i] = v
+	}
+	if errs != nil {
+		return result, errs
+	}
+	return result, nil
+}
+
+// The reverse of IntArrToStrArr
+func StrArrToIntArr(strArr []string) ([]int, error) {
+	return ParseAll(strArr, strconv.Atoi)
+}
@@ -0,0 +1,100 @@
+package stuff
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSliceHelpers(t *testing.T) {
+	nums := []int{1, 2, 3, 4, 5, 6, 7}
+	isEven := func(n int) bool { return n%2 == 0 }
+
+	if got := Map(nums, strconv.Itoa); !reflect.DeepEqual(got, IntArrToStrArr(nums)) {
+		t.Errorf("Map = %v", got)
+	}
+	if got := Filter(nums, isEven); !reflect.DeepEqual(got, []int{2, 4, 6}) {
+		t.Errorf("Filter = %v", got)
+	}
+	sum := Reduce(nums, 0, func(total, n int) int { return total + n })
+	if sum != 28 {
+		t.Errorf("Reduce = %d", sum)
+	}
+	joined := Reduce(nums, "", func(s string, n int) string { return s + strconv.Itoa(n) })
+	if joined != "1234567" {
+		t.Errorf("Reduce to a string = %q", joined)
+	}
+
+	words := []string{"apple", "avocado", "banana", "blueberry", "cherry"}
+	groups := GroupBy(words, func(w string) byte { return w[0] })
+	want := map[byte][]string{'a': {"apple", "avocado"}, 'b': {"banana", "blueberry"}, 'c': {"cherry"}}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("GroupBy = %v", groups)
+	}
+
+	chunks := Chunk(nums, 3)
+	if !reflect.DeepEqual(chunks, [][]int{{1, 2, 3}, {4, 5, 6}, {7}}) {
+		t.Errorf("Chunk = %v", chunks)
+	}
+	// Appending to a chunk mustn't change the next one
+	_ = append(chunks[0], 99)
+	if chunks[1][0] != 4 {
+		t.Error("appending to a chunk overwrote the next")
+	}
+	if got := Chunk([]int{}, 2); got != nil {
+		t.Errorf("Chunk of nothing = %v", got)
+	}
+
+	even, odd := Partition(nums, isEven)
+	if !reflect.DeepEqual(even, []int{2, 4, 6}) || !reflect.DeepEqual(odd, []int{1, 3, 5, 7}) {
+		t.Errorf("Partition = %v %v", even, odd)
+	}
+	if got := Unique([]string{"b", "a", "b", "c", "a"}); !reflect.DeepEqual(got, []string{"b", "a", "c"}) {
+		t.Errorf("Unique = %v", got)
+	}
+
+	pairs := Zip([]string{"one", "two", "three"}, []int{1, 2})
+	wantPairs := []Pair[string, int]{{"one", 1}, {"two", 2}}
+	if !reflect.DeepEqual(pairs, wantPairs) {
+		t.Errorf("Zip = %v", pairs)
+	}
+}
+
+func TestChunkPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Chunk with size 0 didn't panic")
+		}
+	}()
+	Chunk([]int{1}, 0)
+}
+
+func TestStrArrToIntArr(t *testing.T) {
+	nums, err := StrArrToIntArr([]string{"2", "3", "5"})
+	if err != nil || !reflect.DeepEqual(nums, []int{2, 3, 5}) {
+		t.Errorf("got %v, %v", nums, err)
+	}
+
+	nums, err = StrArrToIntArr([]string{"2", "three", "5", "", "11"})
+	var errs IndexErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want IndexErrors", err)
+	}
+	if len(errs) != 2 || errs[0].Index != 1 || errs[1].Index != 3 {
+		t.Errorf("errors = %v", err)
+	}
+	if !errors.Is(errs[0], strconv.ErrSyntax) {
+		t.Errorf("the error lost its cause: %v", errs[0])
+	}
+	if !reflect.DeepEqual(nums, []int{2, 0, 5, 0, 11}) {
+		t.Errorf("the good values were lost: %v", nums)
+	}
+
+	// Any parse function works, like reading dates
+	_, err = ParseAll([]string{"2024-01-31", "2024-02-30"}, Parse)
+	if err == nil || !strings.HasPrefix(err.Error(), "index 1") {
+		t.Errorf("ParseAll dates: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package stuff
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// A NULL can't be scanned into a Date, NullDate is
+// for columns that allow it
+var errNullDate = errors.New("cannot scan NULL into a Date, use NullDate")
+
+// Value saves the date as an ISO 8601 string, which
+// DATE columns accept. A zero date is saved as NULL
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.String(), nil
+}
+
+// Scan reads a DATE column. Drivers give a string,
+// bytes or a time.Time. Strings can have a time after
+// the date, like 2024-01-31 00:00:00, which is
+// ignored. The date keeps its policy, and one without
+// a policy gets Unbounded since stored dates can be
+// in the future
+func (d *Date) Scan(src interface{}) error {
+	base := *d
+	if !base.policy.set {
+		base.policy = Unbounded().kept()
+	}
+	var date Date
+	var err error
+	switch value := src.(type) {
+	case nil:
+		return errNullDate
+	case time.Time:
+		date, err = base.withDate(value.Year(), int(value.Month()), value.Day())
+	case string:
+		date, err = base.parse(ISOLayout, dateOnly(value))
+	case []byte:
+		date, err = base.parse(ISOLayout, dateOnly(string(value)))
+	default:
+		return fmt.Errorf("cannot scan %T into a Date", src)
+	}
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+// Cut the time off a date and time like
+// 2024-01-31T10:00:00Z or 2024-01-31 10:00:00
+func dateOnly(s string) string {
+	if len(s) > len(ISOLayout) && (s[len(ISOLayout)] == 'T' || s[len(ISOLayout)] == ' ') {
+		return s[:len(ISOLayout)]
+	}
+	return s
+}
+
+// NullDate is a Date that can be NULL in a database,
+// like sql.NullTime. Valid is false for NULL
+type NullDate struct {
+	Date  Date
+	Valid bool
+}
+
+// Scan reads a DATE column that can be NULL
+func (n *NullDate) Scan(src interface{}) error {
+	if src == nil {
+		n.Date, n.Valid = Date{policy: n.Date.policy}, false
+		return nil
+	}
+	err := n.Date.Scan(src)
+	n.Valid = err == nil
+	return err
+}
+
+// Value saves the date, or NULL if it isn't valid
+func (n NullDate) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Date.Value()
+}
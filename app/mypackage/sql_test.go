@@ -0,0 +1,145 @@
+package stuff
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Check the interfaces database/sql uses
+var (
+	_ sql.Scanner   = (*Date)(nil)
+	_ driver.Valuer = Date{}
+	_ sql.Scanner   = (*NullDate)(nil)
+	_ driver.Valuer = NullDate{}
+)
+
+func TestScan(t *testing.T) {
+	sources := []interface{}{
+		"2024-01-31",
+		[]byte("2024-01-31"),
+		"2024-01-31 00:00:00",
+		"2024-01-31T00:00:00Z",
+		time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	for _, src := range sources {
+		var date Date
+		if err := date.Scan(src); err != nil {
+			t.Errorf("Scan(%#v): %v", src, err)
+		} else if date.String() != "2024-01-31" {
+			t.Errorf("Scan(%#v) = %v", src, date)
+		}
+	}
+	// Stored dates can be in the future
+	var due Date
+	if err := due.Scan("2999-01-01"); err != nil || due.String() != "2999-01-01" {
+		t.Errorf("Scan future = %v, %v", due, err)
+	}
+	birthdate := Birthdate()
+	birthday := Date{policy: birthdate.kept()}
+	if err := birthday.Scan("2999-01-01"); err == nil {
+		t.Error("Scan allowed the future with Birthdate")
+	}
+	for _, src := range []interface{}{nil, 42, "2024-02-30", "31/01/2024"} {
+		var date Date
+		if err := date.Scan(src); err == nil {
+			t.Errorf("Scan(%#v) = %v, want an error", src, date)
+		}
+	}
+
+	value, err := mustParse(t, "2024-01-31")[0].Value()
+	if err != nil || value != "2024-01-31" {
+		t.Errorf("Value = %#v, %v", value, err)
+	}
+}
+
+func TestNullDate(t *testing.T) {
+	var n NullDate
+	if err := n.Scan("2024-01-31"); err != nil || !n.Valid || n.Date.String() != "2024-01-31" {
+		t.Errorf("Scan date = %+v, %v", n, err)
+	}
+	if err := n.Scan(nil); err != nil || n.Valid || !n.Date.IsZero() {
+		t.Errorf("Scan NULL = %+v, %v", n, err)
+	}
+	if value, err := n.Value(); value != nil || err != nil {
+		t.Errorf("Value of NULL = %#v, %v", value, err)
+	}
+	// The date's own policy is still used
+	birthdate := Birthdate()
+	n = NullDate{Date: Date{policy: birthdate.kept()}}
+	if err := n.Scan("2999-01-01"); err == nil || n.Valid {
+		t.Errorf("Scan with Birthdate = %+v, %v", n, err)
+	}
+}
+
+func TestDateCSV(t *testing.T) {
+	input := "name,start,end\nTrip,2024-01-01,2024-01-31\n\"Move, again\",2024-02-01,\n"
+	codec := DateCSV{Header: true, Columns: []int{1, 2}}
+	header, rows, err := codec.Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(header, []string{"name", "start", "end"}) || len(rows) != 2 {
+		t.Fatalf("got %v and %d rows", header, len(rows))
+	}
+	if rows[0].Dates[2].String() != "2024-01-31" || !rows[1].Dates[2].IsZero() {
+		t.Errorf("rows = %v", rows)
+	}
+	// Other columns are left as text
+	if rows[1].Fields[0] != "Move, again" || !rows[1].Dates[0].IsZero() {
+		t.Errorf("name column = %q, %v", rows[1].Fields[0], rows[1].Dates[0])
+	}
+	var out bytes.Buffer
+	err = codec.Write(&out, header, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != input {
+		t.Errorf("wrote %q, want %q", out.String(), input)
+	}
+
+	us := DateCSV{Layout: "MM/DD/YYYY", Columns: []int{0}}
+	_, rows, err = us.Read(strings.NewReader("12/21/1974,12/21\n"))
+	if err != nil || rows[0].Dates[0].String() != "1974-12-21" || rows[0].Fields[1] != "12/21" {
+		t.Errorf("MM/DD/YYYY: %v %v", rows, err)
+	}
+}
+
+func TestDateCSVErrors(t *testing.T) {
+	input := "start,end,note\n2024-01-01,2024-02-30,fine\nsoon,2024-01-01,not a date\n"
+	_, rows, err := DateCSV{Header: true, Columns: []int{0, 1}}.Read(strings.NewReader(input))
+	var errs CSVErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("got %v, want CSVErrors", err)
+	}
+	want := []string{"row 2, column 2", "row 3, column 1"}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors: %v", len(errs), err)
+	}
+	for i, e := range errs {
+		if !strings.HasPrefix(e.Error(), want[i]) {
+			t.Errorf("error %d = %q, want it to start with %q", i, e, want[i])
+		}
+	}
+	var dateErr *DateError
+	if !errors.As(errs[0], &dateErr) || dateErr.Field != FieldDay {
+		t.Errorf("the first error should be about the day: %v", errs[0])
+	}
+	// The good cells are still read
+	if len(rows) != 2 || rows[1].Dates[1].String() != "2024-01-01" {
+		t.Errorf("rows = %v", rows)
+	}
+
+	// Rows are lines, so a note over three lines moves
+	// the next bad date down to line 5
+	input = "start,note\n2024-01-01,\"one\ntwo\nthree\"\nlater,\n"
+	_, _, err = DateCSV{Header: true, Columns: []int{0}}.Read(strings.NewReader(input))
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Row != 5 {
+		t.Errorf("got %v, want an error on row 5", err)
+	}
+}
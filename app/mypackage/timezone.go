@@ -0,0 +1,96 @@
+package stuff
+
+import "time"
+
+// Return the day a time falls on in a time zone. A nil
+// location uses the time's own zone. The date has the
+// Unbounded policy, so it can be in the future
+func FromTime(t time.Time, loc *time.Location) (Date, error) {
+	return Unbounded().FromTime(t, loc)
+}
+
+// Return the day a time falls on in a time zone,
+// checked against this policy
+func (p Policy) FromTime(t time.Time, loc *time.Location) (Date, error) {
+	if loc != nil {
+		t = t.In(loc)
+	}
+	return p.NewDate(t.Year(), int(t.Month()), t.Day())
+}
+
+// Return today's date in a time zone with the
+// Unbounded policy, so days after it can be worked
+// out from it. The time comes from the package
+// default's clock, see SetDefaultPolicy. A nil
+// location is the local zone
+func Today(loc *time.Location) Date {
+	policy := Unbounded()
+	policy.Clock = DefaultPolicy().Clock
+	return policy.TodayIn(loc)
+}
+
+// Return today's date in a time zone by the policy's
+// clock, so tests can fix the time. The date keeps
+// this policy. A nil location is the local zone
+func (p Policy) TodayIn(loc *time.Location) Date {
+	if loc == nil {
+		loc = time.Local
+	}
+	now := p.now().In(loc)
+	return Date{day: now.Day(), month: int(now.Month()), year: now.Year(), policy: p.kept()}
+}
+
+// Return the first moment of the day in a time zone. This
+// is usually midnight, but when clocks skip midnight
+// for daylight saving it is the moment they jump to,
+// like 01:00. When midnight happens twice the first
+// one is used. A nil location is UTC
+func (d Date) In(loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	utcMidnight := time.Date(d.year, time.Month(d.month), d.day, 0, 0, 0, 0, time.UTC)
+
+	// Midnight in the zone is utcMidnight minus the
+	// zone's offset, for an offset in use around then.
+	// Try each one and keep the earliest that really
+	// is midnight
+	var start time.Time
+	for _, near := range []time.Duration{-24 * time.Hour, 0, 24 * time.Hour} {
+		_, offset := utcMidnight.Add(near).In(loc).Zone()
+		t := utcMidnight.Add(-time.Duration(offset) * time.Second).In(loc)
+		if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && d.isDayOf(t) &&
+			(start.IsZero() || t.Before(start)) {
+			start = t
+		}
+	}
+	if !start.IsZero() {
+		return start
+	}
+
+	// Midnight was skipped, so find the first moment of
+	// the day by binary search. Days are at most a day
+	// and a bit away from utcMidnight
+	lo := utcMidnight.Add(-26 * time.Hour)
+	hi := utcMidnight.Add(26 * time.Hour)
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		if d.hasStartedBy(mid.In(loc)) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return hi.Truncate(time.Second).In(loc)
+}
+
+// Check if a time's clock shows this date
+func (d Date) isDayOf(t time.Time) bool {
+	return t.Year() == d.year && int(t.Month()) == d.month && t.Day() == d.day
+}
+
+// Check if the day has started by a time in its zone
+func (d Date) hasStartedBy(t time.Time) bool {
+	other := Date{day: t.Day(), month: int(t.Month()), year: t.Year()}
+	return !other.Before(d)
+}
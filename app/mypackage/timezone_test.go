@@ -0,0 +1,145 @@
+package stuff
+
+import (
+	"testing"
+	"time"
+	// Tests shouldn't depend on the zones installed
+	_ "time/tzdata"
+)
+
+func loadZone(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestFromTime(t *testing.T) {
+	moment := time.Date(2024, 3, 10, 7, 30, 0, 0, time.UTC)
+	tests := []struct {
+		zone string
+		want string
+	}{
+		{"UTC", "2024-03-10"},
+		{"America/New_York", "2024-03-10"},
+		{"Pacific/Honolulu", "2024-03-09"},
+		{"Asia/Tokyo", "2024-03-10"},
+		{"Pacific/Kiritimati", "2024-03-10"},
+	}
+	for _, test := range tests {
+		date, err := FromTime(moment, loadZone(t, test.zone))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if date.String() != test.want {
+			t.Errorf("%s: got %v, want %s", test.zone, date, test.want)
+		}
+	}
+	old := time.Date(1800, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := Birthdate().FromTime(old, nil); err == nil {
+		t.Error("FromTime allowed a year before the policy")
+	}
+	if date, err := FromTime(old, nil); err != nil || date.String() != "1800-01-01" {
+		t.Errorf("FromTime(1800) = %v, %v", date, err)
+	}
+}
+
+// A time next year is still a date, and adding to it
+// works
+func TestFromTimeInTheFuture(t *testing.T) {
+	future := time.Now().AddDate(1, 0, 0)
+	date, err := FromTime(future, time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if date.Year() != future.UTC().Year() {
+		t.Errorf("FromTime(%v) = %v", future, date)
+	}
+	if _, err := date.AddDays(1); err != nil {
+		t.Error(err)
+	}
+	if _, err := Birthdate().FromTime(future, time.UTC); err == nil {
+		t.Error("Birthdate allowed a time next year")
+	}
+}
+
+func TestInStartOfDay(t *testing.T) {
+	tests := []struct {
+		zone, day, want string
+	}{
+		{"America/New_York", "2024-03-10", "2024-03-10T00:00:00-05:00"},
+		{"America/New_York", "2024-03-11", "2024-03-11T00:00:00-04:00"},
+		// Clocks went from 00:00 to 01:00
+		{"America/Sao_Paulo", "2018-11-04", "2018-11-04T01:00:00-02:00"},
+		{"America/Havana", "2023-03-12", "2023-03-12T01:00:00-04:00"},
+		// Clocks went back from 00:00 to 23:00 the day before
+		{"America/Santiago", "2024-04-07", "2024-04-07T00:00:00-04:00"},
+		// Samoa skipped 30 December 2011 altogether
+		{"Pacific/Apia", "2011-12-31", "2011-12-31T00:00:00+14:00"},
+	}
+	for _, test := range tests {
+		got := mustParse(t, test.day)[0].In(loadZone(t, test.zone))
+		if got.Format(time.RFC3339) != test.want {
+			t.Errorf("%s in %s = %s, want %s", test.day, test.zone,
+				got.Format(time.RFC3339), test.want)
+		}
+	}
+}
+
+// Every day's start should be on that day, with the
+// moment before it on the day before
+func TestInRoundTrips(t *testing.T) {
+	policy := Policy{AllowFuture: true}
+	for _, zone := range []string{"America/New_York", "America/Sao_Paulo",
+		"America/Santiago", "Asia/Beirut", "Australia/Lord_Howe", "Europe/London"} {
+		loc := loadZone(t, zone)
+		start, _ := policy.NewDate(2017, 1, 1)
+		end, _ := policy.NewDate(2024, 12, 31)
+		r, _ := NewDateRange(start, end)
+		r.Each(func(d Date) bool {
+			begin := d.In(loc)
+			if !d.isDayOf(begin) {
+				t.Errorf("%s: %v starts at %v", zone, d, begin)
+				return false
+			}
+			if before := begin.Add(-time.Nanosecond).In(loc); d.isDayOf(before) {
+				t.Errorf("%s: %v has begun at %v, before %v", zone, d, before, begin)
+				return false
+			}
+			return true
+		})
+	}
+}
+
+func TestToday(t *testing.T) {
+	policy := Unbounded()
+	policy.Clock = func() time.Time {
+		return time.Date(2024, 6, 15, 23, 30, 0, 0, time.UTC)
+	}
+	if got := policy.TodayIn(time.UTC); got.String() != "2024-06-15" {
+		t.Errorf("TodayIn(UTC) = %v", got)
+	}
+	if got := policy.TodayIn(loadZone(t, "Asia/Tokyo")); got.String() != "2024-06-16" {
+		t.Errorf("TodayIn(Tokyo) = %v", got)
+	}
+
+	// Today reads the default's clock, here just after
+	// midnight in UTC while New York is still on the
+	// day before
+	birthdate := Birthdate()
+	birthdate.Clock = func() time.Time {
+		return time.Date(2024, 6, 16, 0, 15, 0, 0, time.UTC)
+	}
+	testDefaultPolicy(t, birthdate)
+	if got := Today(time.UTC); got.String() != "2024-06-16" {
+		t.Errorf("Today(UTC) = %v", got)
+	}
+	if got := Today(loadZone(t, "America/New_York")); got.String() != "2024-06-15" {
+		t.Errorf("Today(New York) = %v", got)
+	}
+	if _, err := Today(nil).AddDays(1); err != nil {
+		t.Errorf("Today(nil) + 1 day: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// Write a value to the browser as JSON
+func writeJSON(writer http.ResponseWriter, status int, v interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	err := json.NewEncoder(writer).Encode(v)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// Send an error message as JSON
+func writeJSONError(writer http.ResponseWriter, status int, err error) {
+	writeJSON(writer, status, map[string]string{"error": err.Error()})
+}
+
+// Returns the to dos in a list as JSON
+func apiTodosHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	if request.Method != http.MethodGet {
+		writeJSONError(writer, http.StatusMethodNotAllowed, errMethod)
+		return
+	}
+	list := listName(request)
+	writeJSON(writer, http.StatusOK, map[string]interface{}{
+		"list":  list,
+		"todos": store.Items(list),
+	})
+}
+
+// Applies a bulk action sent as JSON like
+// {"action": "move", "ids": [1, 2], "target": "work"}
+func apiBulkHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	if request.Method != http.MethodPost {
+		writeJSONError(writer, http.StatusMethodNotAllowed, errMethod)
+		return
+	}
+	var op BulkOp
+	err := json.NewDecoder(request.Body).Decode(&op)
+	if err != nil {
+		writeJSONError(writer, http.StatusBadRequest, err)
+		return
+	}
+	if op.List == "" {
+		op.List = defaultList
+	}
+	changed, err := store.Bulk(op)
+	if err != nil {
+		writeJSONError(writer, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(writer, http.StatusOK, map[string]int{"changed": changed})
+}
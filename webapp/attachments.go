@@ -0,0 +1,292 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Attachment is a file uploaded to a to do. The file is
+// saved under its random ID so the uploaded name never
+// touches the file system
+type Attachment struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Size int64  `json:"size"`
+}
+
+// The largest upload and the types that are allowed.
+// A type like image/* allows every image
+type uploadConfig struct {
+	maxSize int64
+	allowed []string
+}
+
+// The upload settings picked on the command line
+var uploads = uploadConfig{
+	maxSize: 10 << 20,
+	allowed: []string{"image/png", "image/jpeg", "image/gif",
+		"text/plain", "application/pdf"},
+}
+
+// The directory attachments are saved in
+func (s *Store) attachmentDir() string {
+	return filepath.Join(s.dir, "attachments")
+}
+
+// Check if a media type is on the allowlist
+func (c uploadConfig) allows(mediaType string) bool {
+	for _, allowed := range c.allowed {
+		if allowed == mediaType {
+			return true
+		}
+		if prefix := strings.TrimSuffix(allowed, "*"); prefix != allowed &&
+			strings.HasPrefix(mediaType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check an uploaded file and save it in dir. The type
+// is sniffed from the contents rather than trusting
+// what the browser says
+func saveUpload(dir string, config uploadConfig, header *multipart.FileHeader) (Attachment, error) {
+	if header.Size > config.maxSize {
+		return Attachment{}, fmt.Errorf("%s is bigger than %d bytes", header.Filename, config.maxSize)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return Attachment{}, err
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return Attachment{}, err
+	}
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil || !config.allows(mediaType) {
+		return Attachment{}, fmt.Errorf("%s files are not allowed", mediaType)
+	}
+
+	id, err := randomBytes(16)
+	if err != nil {
+		return Attachment{}, err
+	}
+	attachment := Attachment{
+		ID:   hex.EncodeToString(id),
+		Name: filepath.Base(header.Filename),
+		Type: mediaType,
+	}
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return Attachment{}, err
+	}
+	out, err := os.OpenFile(filepath.Join(dir, attachment.ID),
+		os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return Attachment{}, err
+	}
+	// Write what was sniffed then the rest, stopping
+	// if the file is bigger than it claimed
+	written, err := io.Copy(out, io.LimitReader(io.MultiReader(
+		strings.NewReader(string(head[:n])), file), config.maxSize+1))
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && written > config.maxSize {
+		err = fmt.Errorf("%s is bigger than %d bytes", header.Filename, config.maxSize)
+	}
+	if err != nil {
+		os.Remove(filepath.Join(dir, attachment.ID))
+		return Attachment{}, err
+	}
+	attachment.Size = written
+	return attachment, nil
+}
+
+// Save every file sent in the attachment field. If one
+// fails the ones already saved are removed
+func saveUploads(dir string, request *http.Request) ([]Attachment, error) {
+	if request.MultipartForm == nil {
+		return nil, nil
+	}
+	var saved []Attachment
+	for _, header := range request.MultipartForm.File["attachment"] {
+		attachment, err := saveUpload(dir, uploads, header)
+		if err != nil {
+			removeAttachments(dir, saved)
+			return nil, err
+		}
+		saved = append(saved, attachment)
+	}
+	return saved, nil
+}
+
+// Delete the files of some attachments
+func removeAttachments(dir string, attachments []Attachment) {
+	for _, attachment := range attachments {
+		err := os.Remove(filepath.Join(dir, attachment.ID))
+		if err != nil && !os.IsNotExist(err) {
+			log.Println(err)
+		}
+	}
+}
+
+// Delete files in the attachment directory that no to
+// do refers to. This catches files left behind by a
+// crash, so it runs before the server starts
+func (s *Store) RemoveOrphanedAttachments() error {
+	entries, err := os.ReadDir(s.attachmentDir())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	used := map[string]bool{}
+	for _, attachment := range s.data.attachments() {
+		used[attachment.ID] = true
+	}
+	s.mu.Unlock()
+
+	for _, entry := range entries {
+		if !used[entry.Name()] {
+			err := os.Remove(filepath.Join(s.attachmentDir(), entry.Name()))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Find an attachment by its ID
+func (s *Store) FindAttachment(id string) (Attachment, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, attachment := range s.data.attachments() {
+		if attachment.ID == id {
+			return attachment, true
+		}
+	}
+	return Attachment{}, false
+}
+
+// Every attachment of every to do
+func (d storeData) attachments() []Attachment {
+	var all []Attachment
+	for _, items := range d.Lists {
+		for _, item := range items {
+			all = append(all, item.Attachments...)
+		}
+	}
+	return all
+}
+
+// Check an attachment ID can't name a file outside
+// the attachment directory
+func validAttachmentID(id string) bool {
+	return id != "" && !strings.HasPrefix(id, ".") && filepath.Base(id) == id
+}
+
+// Parse a form that may hold uploads
+func parseUploadForm(request *http.Request) error {
+	err := request.ParseMultipartForm(1 << 20)
+	if err == http.ErrNotMultipart {
+		return request.ParseForm()
+	}
+	return err
+}
+
+// Shows the form for changing a to do
+func editHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	id, _ := strconv.Atoi(request.FormValue("id"))
+	item, list, ok := store.Find(id)
+	if !ok {
+		http.NotFound(writer, request)
+		return
+	}
+	tmpl, err := template.ParseFiles("edit.html")
+	errorCheck(err)
+	err = tmpl.Execute(writer, struct {
+		Item
+		List string
+	}{item, list})
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// Saves the edit form: new text, new uploads and the
+// attachments that were ticked for removal
+func updateHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	if request.Method != http.MethodPost {
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	err := parseUploadForm(request)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(request.FormValue("id"))
+	if err != nil {
+		http.Error(writer, "bad id", http.StatusBadRequest)
+		return
+	}
+	added, err := saveUploads(store.attachmentDir(), request)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = store.Update(id, request.FormValue("todo"), added, request.Form["remove"])
+	if err != nil {
+		removeAttachments(store.attachmentDir(), added)
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_, list, _ := store.Find(id)
+	http.Redirect(writer, request, listURL(list), http.StatusFound)
+}
+
+// Sends an attachment as a download. Content-Disposition
+// stops the browser rendering uploaded HTML or SVG
+func attachmentHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	attachment, ok := store.FindAttachment(request.FormValue("id"))
+	if !ok {
+		http.NotFound(writer, request)
+		return
+	}
+	file, err := os.Open(filepath.Join(store.attachmentDir(), attachment.ID))
+	if err != nil {
+		http.NotFound(writer, request)
+		return
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", attachment.Type)
+	writer.Header().Set("Content-Disposition", mime.FormatMediaType("attachment",
+		map[string]string{"filename": attachment.Name}))
+	http.ServeContent(writer, request, "", stat.ModTime(), file)
+}
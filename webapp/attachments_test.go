@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Build a multipart request with one file per name
+func uploadRequest(t *testing.T, files map[string][]byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, contents := range files {
+		part, err := form.CreateFormFile("attachment", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(contents)
+	}
+	form.Close()
+	request := httptest.NewRequest(http.MethodPost, "/create", &body)
+	request.Header.Set("Content-Type", form.FormDataContentType())
+	if err := parseUploadForm(request); err != nil {
+		t.Fatal(err)
+	}
+	return request
+}
+
+func TestSaveUploadsChecksTypeAndSize(t *testing.T) {
+	dir := t.TempDir()
+	old := uploads
+	defer func() { uploads = old }()
+	uploads = uploadConfig{maxSize: 64, allowed: []string{"text/plain", "image/*"}}
+
+	saved, err := saveUploads(dir, uploadRequest(t, map[string][]byte{
+		"../../notes.txt": []byte("hello"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved[0].Name != "notes.txt" || saved[0].Type != "text/plain" || saved[0].Size != 5 {
+		t.Errorf("saved %+v", saved[0])
+	}
+
+	bad := map[string][]byte{
+		"page.txt": []byte("<html><script>alert(1)</script>"),
+		"big.txt":  []byte(strings.Repeat("x", 65)),
+	}
+	for name, contents := range bad {
+		_, err := saveUploads(dir, uploadRequest(t, map[string][]byte{name: contents}))
+		if err == nil {
+			t.Errorf("%s was accepted", name)
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("%d files on disk, want 1", len(entries))
+	}
+}
+
+func TestAttachmentsRemovedWithTheirToDo(t *testing.T) {
+	s := testStore(t, "a", "b")
+	write := func(id string) Attachment {
+		os.MkdirAll(s.attachmentDir(), 0700)
+		os.WriteFile(filepath.Join(s.attachmentDir(), id), []byte(id), 0600)
+		return Attachment{ID: id, Name: id + ".txt"}
+	}
+	if err := s.Attach(1, []Attachment{write("one"), write("two")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Attach(2, []Attachment{write("three")}); err != nil {
+		t.Fatal(err)
+	}
+	write("orphan")
+	exists := func(id string) bool {
+		_, err := os.Stat(filepath.Join(s.attachmentDir(), id))
+		return err == nil
+	}
+
+	if err := s.Update(1, "", nil, []string{"one"}); err != nil {
+		t.Fatal(err)
+	}
+	if exists("one") || !exists("two") {
+		t.Error("removing an attachment didn't delete just its file")
+	}
+	if _, err := s.Bulk(BulkOp{Action: BulkDelete, IDs: []int{1}}); err != nil {
+		t.Fatal(err)
+	}
+	if exists("two") || !exists("three") {
+		t.Error("deleting a to do didn't delete just its files")
+	}
+	if err := s.RemoveOrphanedAttachments(); err != nil {
+		t.Fatal(err)
+	}
+	if exists("orphan") || !exists("three") {
+		t.Error("orphan sweep removed the wrong files")
+	}
+	if _, ok := s.FindAttachment("three"); !ok {
+		t.Error("attachment three is missing")
+	}
+}
+
+func TestCreateWithAttachmentsIsOneChange(t *testing.T) {
+	s := testStore(t)
+	var seen []int
+	s.OnChange(func(entry HistoryEntry) {
+		item, _, _ := s.Find(entry.IDs[0])
+		seen = append(seen, len(item.Attachments))
+	})
+	parent, err := s.Create(defaultList, 0, "a", []Attachment{{ID: "one"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Create("ignored", parent.ID, "a1", []Attachment{{ID: "two"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Create(defaultList, 99, "orphan", nil); err != errNotFound {
+		t.Errorf("missing parent gave %v", err)
+	}
+	history, _ := s.History()
+	if len(history) != 2 || len(seen) != 2 || seen[0] != 1 || seen[1] != 1 {
+		t.Errorf("got %d history entries, listeners saw %v", len(history), seen)
+	}
+	if got := s.Items(defaultList); len(got) != 2 || got[1].Parent != parent.ID {
+		t.Errorf("default list is %+v", got)
+	}
+}
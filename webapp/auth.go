@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Token scopes. A read token can only make GET requests
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// Token is an API credential. Only the SHA-256 hash of
+// the secret is kept so a leaked tokens.json can't be
+// used to log in
+type Token struct {
+	ID      string    `json:"id"`
+	User    string    `json:"user"`
+	Name    string    `json:"name"`
+	Scope   string    `json:"scope"`
+	Hash    string    `json:"hash,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// TokenStore keeps the API tokens in a JSON file
+type TokenStore struct {
+	mu     sync.Mutex
+	path   string
+	tokens []Token
+	now    func() time.Time
+}
+
+// principal is who made a request and what they may do
+type principal struct {
+	User  string
+	Scope string
+}
+
+var errNotFound = errors.New("not found")
+
+// The API tokens and the users allowed to log in with
+// HTTP Basic auth. The passwords are kept as hashes
+var (
+	tokens     *TokenStore
+	basicUsers = map[string][]byte{}
+)
+
+// Load the tokens, starting empty if the file is missing
+func OpenTokenStore(fileName string) (*TokenStore, error) {
+	ts := &TokenStore{path: fileName, now: time.Now}
+	contents, err := os.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return ts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(contents, &ts.tokens)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", fileName, err)
+	}
+	return ts, nil
+}
+
+// Hash a secret the way it is stored
+func hashSecret(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
+
+// Return n random bytes
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	return b, err
+}
+
+// Create a token for a user. The secret is returned
+// once and can't be recovered afterwards
+func (ts *TokenStore) Create(user, name, scope string) (string, Token, error) {
+	if user == "" {
+		return "", Token{}, errors.New("tokens need a user")
+	}
+	if scope != ScopeRead && scope != ScopeWrite {
+		return "", Token{}, fmt.Errorf("unknown scope %q", scope)
+	}
+	id, err := randomBytes(8)
+	if err != nil {
+		return "", Token{}, err
+	}
+	secretBytes, err := randomBytes(32)
+	if err != nil {
+		return "", Token{}, err
+	}
+	secret := "todo_" + base64.RawURLEncoding.EncodeToString(secretBytes)
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	token := Token{
+		ID:      hex.EncodeToString(id),
+		User:    user,
+		Name:    name,
+		Scope:   scope,
+		Hash:    hex.EncodeToString(hashSecret(secret)),
+		Created: ts.now(),
+	}
+	err = writeJSONFile(ts.path, append(ts.tokens, token))
+	if err != nil {
+		return "", Token{}, err
+	}
+	ts.tokens = append(ts.tokens, token)
+	token.Hash = ""
+	return secret, token, nil
+}
+
+// Return a user's tokens without their hashes
+func (ts *TokenStore) List(user string) []Token {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	list := []Token{}
+	for _, token := range ts.tokens {
+		if token.User == user {
+			token.Hash = ""
+			list = append(list, token)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Created.Before(list[j].Created)
+	})
+	return list
+}
+
+// Delete one of a user's tokens
+func (ts *TokenStore) Revoke(user, id string) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for i, token := range ts.tokens {
+		if token.ID == id && token.User == user {
+			kept := append(append([]Token(nil), ts.tokens[:i]...), ts.tokens[i+1:]...)
+			err := writeJSONFile(ts.path, kept)
+			if err != nil {
+				return err
+			}
+			ts.tokens = kept
+			return nil
+		}
+	}
+	return errNotFound
+}
+
+// Find the token a secret belongs to
+func (ts *TokenStore) Lookup(secret string) (Token, bool) {
+	hash := hashSecret(secret)
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for _, token := range ts.tokens {
+		stored, err := hex.DecodeString(token.Hash)
+		if err == nil && subtle.ConstantTimeCompare(stored, hash) == 1 {
+			return token, true
+		}
+	}
+	return Token{}, false
+}
+
+// Return the number of tokens
+func (ts *TokenStore) Len() int {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return len(ts.tokens)
+}
+
+// Read Basic auth users from a list like
+// "alice:secret,bob:hunter2"
+func parseBasicUsers(list string) (map[string][]byte, error) {
+	users := map[string][]byte{}
+	for _, pair := range strings.Split(list, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		user, password, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || user == "" || password == "" {
+			return nil, fmt.Errorf("basic auth entry %q is not user:password", pair)
+		}
+		users[user] = hashSecret(password)
+	}
+	return users, nil
+}
+
+// Auth is only enforced once a Basic user or a token
+// exists so a fresh local install keeps working. Until
+// then anyone who can reach the server can read and
+// change every list, so only the admin pages are shut,
+// see requireAdmin, and the server says so at startup
+func authEnabled() bool {
+	return len(basicUsers) > 0 || tokens.Len() > 0
+}
+
+// Work out who sent a request from its bearer token
+// or Basic auth header
+func authenticate(request *http.Request) (principal, bool) {
+	header := request.Header.Get("Authorization")
+	if secret := strings.TrimPrefix(header, "Bearer "); secret != header {
+		token, ok := tokens.Lookup(strings.TrimSpace(secret))
+		return principal{User: token.User, Scope: token.Scope}, ok
+	}
+	user, password, ok := request.BasicAuth()
+	if !ok {
+		return principal{}, false
+	}
+	stored, ok := basicUsers[user]
+	if !ok || subtle.ConstantTimeCompare(stored, hashSecret(password)) != 1 {
+		return principal{}, false
+	}
+	return principal{User: user, Scope: ScopeWrite}, true
+}
+
+// Context key for the principal of a request
+type principalKey struct{}
+
+// Attach the principal to a request's context
+func withPrincipal(ctx context.Context, who principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, who)
+}
+
+// Get the logged in principal of a request
+func principalFrom(ctx context.Context) (principal, bool) {
+	who, ok := ctx.Value(principalKey{}).(principal)
+	return who, ok
+}
+
+// Reading methods only need a read scope
+func readOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// Wrap an API handler so it needs a token or Basic
+// auth. Read tokens are turned away from anything
+// that changes data
+func requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if !readOnlyMethod(request.Method) && !sentByScript(request) && !sameOrigin(request) {
+			writeJSONError(writer, http.StatusForbidden, errors.New("cross-site request"))
+			return
+		}
+		if !authEnabled() {
+			next(writer, request)
+			return
+		}
+		who, ok := authenticate(request)
+		if !ok {
+			writer.Header().Set("WWW-Authenticate", `Bearer realm="todos"`)
+			if len(basicUsers) > 0 {
+				writer.Header().Add("WWW-Authenticate", `Basic realm="todos"`)
+			}
+			writeJSONError(writer, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+		if who.Scope != ScopeWrite && !readOnlyMethod(request.Method) {
+			writeJSONError(writer, http.StatusForbidden, errors.New("token is read-only"))
+			return
+		}
+		next(writer, request.WithContext(withPrincipal(request.Context(), who)))
+	}
+}
+
+// Admin pages change server wide settings, so unlike
+// the rest they stay shut until a user or token exists
+func requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if !authEnabled() {
+			writeJSONError(writer, http.StatusForbidden,
+				errors.New("set TODO_BASIC_AUTH or create a token to use the admin pages"))
+			return
+		}
+		next(writer, request)
+	}
+}
+
+// A browser sends Basic auth by itself, so another site
+// could post a form here as the user. Browsers name the
+// page a form came from in Origin, or Referer on older
+// ones, and it must be this server. Requests with
+// neither come from scripts, not pages
+func sameOrigin(request *http.Request) bool {
+	source := request.Header.Get("Origin")
+	if source == "" {
+		source = request.Header.Get("Referer")
+	}
+	if source == "" {
+		return true
+	}
+	from, err := url.Parse(source)
+	return err == nil && from.Host == request.Host
+}
+
+// Bearer tokens are never sent by the browser on its
+// own, so requests carrying one can't be forged
+func sentByScript(request *http.Request) bool {
+	return strings.HasPrefix(request.Header.Get("Authorization"), "Bearer ")
+}
+
+// GET lists your tokens, POST creates one from
+// {"name": "backup script", "scope": "read"} and
+// DELETE ?id= revokes one
+func apiTokensHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	who, ok := principalFrom(request.Context())
+	if !ok {
+		writeJSONError(writer, http.StatusUnauthorized,
+			errors.New("tokens can only be managed by a logged in user"))
+		return
+	}
+	switch request.Method {
+	case http.MethodGet:
+		writeJSON(writer, http.StatusOK, map[string][]Token{"tokens": tokens.List(who.User)})
+	case http.MethodPost:
+		var body struct {
+			Name  string `json:"name"`
+			Scope string `json:"scope"`
+		}
+		err := json.NewDecoder(request.Body).Decode(&body)
+		if err != nil {
+			writeJSONError(writer, http.StatusBadRequest, err)
+			return
+		}
+		if body.Scope == "" {
+			body.Scope = ScopeWrite
+		}
+		secret, token, err := tokens.Create(who.User, body.Name, body.Scope)
+		if err != nil {
+			writeJSONError(writer, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(writer, http.StatusCreated, map[string]interface{}{
+			"token":  token,
+			"secret": secret,
+		})
+	case http.MethodDelete:
+		err := tokens.Revoke(who.User, request.FormValue("id"))
+		if err == errNotFound {
+			writeJSONError(writer, http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			writeJSONError(writer, http.StatusInternalServerError, err)
+			return
+		}
+		writer.WriteHeader(http.StatusNoContent)
+	default:
+		writeJSONError(writer, http.StatusMethodNotAllowed, errMethod)
+	}
+}
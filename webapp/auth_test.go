@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Point the package auth state at a fresh token store
+func testAuth(t *testing.T, users string) {
+	t.Helper()
+	var err error
+	tokens, err = OpenTokenStore(filepath.Join(t.TempDir(), "tokens.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	basicUsers, err = parseBasicUsers(users)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTokensAreHashedAndRevocable(t *testing.T) {
+	testAuth(t, "")
+	secret, token, err := tokens.Create("alice", "script", ScopeRead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := OpenTokenStore(tokens.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(reopened.tokens[0].Hash, secret) || reopened.tokens[0].Hash == "" {
+		t.Errorf("stored hash %q does not look hashed", reopened.tokens[0].Hash)
+	}
+	if found, ok := reopened.Lookup(secret); !ok || found.ID != token.ID {
+		t.Errorf("lookup found %v, %v", found, ok)
+	}
+	if list := tokens.List("bob"); len(list) != 0 {
+		t.Errorf("bob can see %v", list)
+	}
+	if err := tokens.Revoke("bob", token.ID); err != errNotFound {
+		t.Errorf("bob revoked alice's token: %v", err)
+	}
+	if err := tokens.Revoke("alice", token.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tokens.Lookup(secret); ok {
+		t.Error("revoked token still works")
+	}
+}
+
+func TestRequireAuth(t *testing.T) {
+	testAuth(t, "admin:s3cret")
+	readSecret, _, err := tokens.Create("alice", "", ScopeRead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := requireAuth(func(writer http.ResponseWriter, request *http.Request) {
+		who, _ := principalFrom(request.Context())
+		writer.Write([]byte(who.User))
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		auth   func(*http.Request)
+		status int
+	}{
+		{"no credentials", http.MethodGet, func(*http.Request) {}, http.StatusUnauthorized},
+		{"bad token", http.MethodGet, bearer("todo_nope"), http.StatusUnauthorized},
+		{"read token get", http.MethodGet, bearer(readSecret), http.StatusOK},
+		{"read token post", http.MethodPost, bearer(readSecret), http.StatusForbidden},
+		{"basic auth post", http.MethodPost, func(r *http.Request) { r.SetBasicAuth("admin", "s3cret") }, http.StatusOK},
+		{"wrong password", http.MethodGet, func(r *http.Request) { r.SetBasicAuth("admin", "guess") }, http.StatusUnauthorized},
+		{"form from this site", http.MethodPost, fromPage("http://example.com/interact"), http.StatusOK},
+		{"form from another site", http.MethodPost, fromPage("https://evil.example/"), http.StatusForbidden},
+		{"page from another site", http.MethodGet, fromPage("https://evil.example/"), http.StatusOK},
+	}
+	for _, test := range tests {
+		request := httptest.NewRequest(test.method, "/api/todos", nil)
+		test.auth(request)
+		recorder := httptest.NewRecorder()
+		handler(recorder, request)
+		if recorder.Code != test.status {
+			t.Errorf("%s: got status %d, want %d", test.name, recorder.Code, test.status)
+		}
+	}
+}
+
+// Basic auth sent by a browser from a page at origin
+func fromPage(origin string) func(*http.Request) {
+	return func(r *http.Request) {
+		r.SetBasicAuth("admin", "s3cret")
+		r.Header.Set("Origin", origin)
+	}
+}
+
+func bearer(secret string) func(*http.Request) {
+	return func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+secret)
+	}
+}
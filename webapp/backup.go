@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Snapshots are named with the time they were taken
+// so sorting the names sorts them oldest first
+const (
+	snapshotPrefix = "todos-"
+	snapshotSuffix = ".json"
+	snapshotLayout = "20060102T150405.000Z"
+)
+
+// Where snapshots go and how many to keep
+type backupConfig struct {
+	dir      string
+	interval time.Duration
+	keep     int
+}
+
+// The backup settings picked on the command line
+var backups backupConfig
+
+// Write the current data to a new timestamped file
+// in dir and return its path
+func (s *Store) Snapshot(dir string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.snapshot(dir)
+}
+
+// Write a snapshot while already holding the lock
+func (s *Store) snapshot(dir string) (string, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", err
+	}
+	name := snapshotPrefix + s.now().UTC().Format(snapshotLayout) + snapshotSuffix
+	path := filepath.Join(dir, name)
+	err = writeJSONFile(path, s.data)
+	if err != nil {
+		return "", err
+	}
+	return path, copyAttachments(s.data.attachments(), s.attachmentDir(),
+		snapshotAttachmentDir(dir))
+}
+
+// Attachment files are kept beside the snapshots so
+// a restore can bring back files deleted since then
+func snapshotAttachmentDir(dir string) string {
+	return filepath.Join(dir, "attachments")
+}
+
+// Copy attachment files from one directory to another.
+// A file never changes once uploaded so ones already
+// copied are skipped, as are ones missing from from
+func copyAttachments(attachments []Attachment, from, to string) error {
+	for _, attachment := range attachments {
+		dest := filepath.Join(to, attachment.ID)
+		if _, err := os.Stat(dest); err == nil {
+			continue
+		}
+		err := copyFile(filepath.Join(from, attachment.ID), dest)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Copy a file through a temporary file so a crash
+// never leaves half of it behind
+func copyFile(from, to string) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	err = os.MkdirAll(filepath.Dir(to), 0700)
+	if err != nil {
+		return err
+	}
+	out, err := os.CreateTemp(filepath.Dir(to), filepath.Base(to)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(out.Name(), to)
+	}
+	if err != nil {
+		os.Remove(out.Name())
+	}
+	return err
+}
+
+// Return the snapshots in dir oldest first
+func listSnapshots(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() &&
+			strings.HasPrefix(name, snapshotPrefix) &&
+			strings.HasSuffix(name, snapshotSuffix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Delete the oldest snapshots so only keep are left.
+// A keep below 1 keeps every snapshot
+func pruneSnapshots(dir string, keep int) error {
+	if keep < 1 {
+		return nil
+	}
+	names, err := listSnapshots(dir)
+	if err != nil {
+		return err
+	}
+	for len(names) > keep {
+		err := os.Remove(filepath.Join(dir, names[0]))
+		if err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return pruneSnapshotAttachments(dir, names)
+}
+
+// Delete the kept attachment files that none of the
+// remaining snapshots refer to. If a snapshot can't be
+// read nothing is deleted
+func pruneSnapshotAttachments(dir string, names []string) error {
+	entries, err := os.ReadDir(snapshotAttachmentDir(dir))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	used := map[string]bool{}
+	for _, name := range names {
+		data, err := validateSnapshot(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		for _, attachment := range data.attachments() {
+			used[attachment.ID] = true
+		}
+	}
+	for _, entry := range entries {
+		if !used[entry.Name()] {
+			err := os.Remove(filepath.Join(snapshotAttachmentDir(dir), entry.Name()))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Take a snapshot and then apply the retention limit
+func backup(s *Store, config backupConfig) (string, error) {
+	path, err := s.Snapshot(config.dir)
+	if err != nil {
+		return "", err
+	}
+	return path, pruneSnapshots(config.dir, config.keep)
+}
+
+// Take a snapshot every interval until stop is closed
+func runBackups(s *Store, config backupConfig, stop <-chan struct{}) {
+	ticker := time.NewTicker(config.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			path, err := backup(s, config)
+			if err != nil {
+				log.Println("backup failed:", err)
+				continue
+			}
+			log.Println("backup written to", path)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Read a snapshot and check it is safe to load
+func validateSnapshot(fileName string) (storeData, error) {
+	var data storeData
+	contents, err := os.ReadFile(fileName)
+	if err != nil {
+		return data, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&data)
+	if err != nil {
+		return data, fmt.Errorf("%s is not a snapshot: %w", fileName, err)
+	}
+	if data.Lists == nil {
+		return data, fmt.Errorf("%s has no lists", fileName)
+	}
+
+	// Every id must be unique and below the next id
+	seen := map[int]bool{}
+	for name, items := range data.Lists {
+		if name == "" {
+			return data, fmt.Errorf("%s has a list with no name", fileName)
+		}
+		for _, item := range items {
+			if item.ID < 1 || item.ID >= data.NextID {
+				return data, fmt.Errorf("%s: item id %d is out of range", fileName, item.ID)
+			}
+			if seen[item.ID] {
+				return data, fmt.Errorf("%s: item id %d is used twice", fileName, item.ID)
+			}
+			seen[item.ID] = true
+			for _, attachment := range item.Attachments {
+				if !validAttachmentID(attachment.ID) {
+					return data, fmt.Errorf("%s: item %d has a bad attachment id %q",
+						fileName, item.ID, attachment.ID)
+				}
+			}
+		}
+	}
+
+	// Subtasks must sit in the same list as their parent
+	// and following the parents must never loop
+	parents := map[int]int{}
+	for name, items := range data.Lists {
+		for _, item := range items {
+			if item.Parent == 0 {
+				continue
+			}
+			list, _, ok := data.find(item.Parent)
+			if !ok || list != name {
+				return data, fmt.Errorf("%s: item %d has a missing parent %d",
+					fileName, item.ID, item.Parent)
+			}
+			parents[item.ID] = item.Parent
+		}
+	}
+	for id := range parents {
+		steps := 0
+		for parent := parents[id]; parent != 0; parent = parents[parent] {
+			steps++
+			if steps > len(parents) {
+				return data, fmt.Errorf("%s: item %d is its own ancestor", fileName, id)
+			}
+		}
+	}
+	return data, nil
+}
+
+// Drop the attachments whose files are not in dir and
+// return them
+func (d storeData) dropMissingAttachments(dir string) []Attachment {
+	var dropped []Attachment
+	for _, items := range d.Lists {
+		for i := range items {
+			var kept []Attachment
+			for _, attachment := range items[i].Attachments {
+				if _, err := os.Stat(filepath.Join(dir, attachment.ID)); err != nil {
+					dropped = append(dropped, attachment)
+					continue
+				}
+				kept = append(kept, attachment)
+			}
+			items[i].Attachments = kept
+		}
+	}
+	return dropped
+}
+
+// Replace the store with a snapshot after checking
+// it. The current data is snapshotted first so the
+// restore can be undone. Attachment files deleted since
+// the snapshot are copied back from the ones kept with
+// it, and attachments with no file left are dropped
+func (s *Store) Restore(fileName, backupDir string) error {
+	data, err := validateSnapshot(fileName)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.snapshot(backupDir)
+	if err != nil {
+		return err
+	}
+	err = copyAttachments(data.attachments(),
+		snapshotAttachmentDir(filepath.Dir(fileName)), s.attachmentDir())
+	if err != nil {
+		return err
+	}
+	for _, attachment := range data.dropMissingAttachments(s.attachmentDir()) {
+		log.Printf("restore: dropped attachment %s (%s), its file is gone",
+			attachment.ID, attachment.Name)
+	}
+	err = writeJSONFile(s.path(), data)
+	if err != nil {
+		return err
+	}
+	s.data = data
+	return appendHistory(s.historyFile, HistoryEntry{Time: s.now(),
+		Action: "restore", Text: filepath.Base(fileName)})
+}
+
+// GET lists the snapshots and POST takes a new one
+func adminBackupHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	switch request.Method {
+	case http.MethodGet:
+		names, err := listSnapshots(backups.dir)
+		if err != nil {
+			writeJSONError(writer, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(writer, http.StatusOK, map[string][]string{"snapshots": names})
+	case http.MethodPost:
+		path, err := backup(store, backups)
+		if err != nil {
+			writeJSONError(writer, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(writer, http.StatusCreated,
+			map[string]string{"snapshot": filepath.Base(path)})
+	default:
+		writeJSONError(writer, http.StatusMethodNotAllowed, errMethod)
+	}
+}
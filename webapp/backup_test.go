@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRetentionAndRestore(t *testing.T) {
+	s := testStore(t, "a", "b")
+	dir := t.TempDir()
+	config := backupConfig{dir: dir, keep: 2}
+
+	// Give each snapshot its own timestamp
+	clock := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	first, err := backup(s, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Add(defaultList, "c"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := backup(s, config); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names, err := listSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("kept %d snapshots, want 2", len(names))
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Errorf("oldest snapshot %s was not pruned", first)
+	}
+
+	// Restore the newest snapshot after a delete
+	if _, err := s.Bulk(BulkOp{Action: BulkDelete, IDs: []int{1, 2, 3}}); err != nil {
+		t.Fatal(err)
+	}
+	err = s.Restore(filepath.Join(dir, names[1]), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := itemTexts(s.Items(defaultList)); len(got) != 3 {
+		t.Errorf("restored %q, want 3 items", got)
+	}
+}
+
+func TestRestoreRejectsBadSnapshots(t *testing.T) {
+	bad := map[string]string{
+		"not json":     "{",
+		"no lists":     `{"next_id": 1}`,
+		"unknown":      `{"next_id": 1, "lists": {}, "extra": true}`,
+		"duplicate id": `{"next_id": 3, "lists": {"a": [{"id": 1}], "b": [{"id": 1}]}}`,
+		"id too big":   `{"next_id": 2, "lists": {"a": [{"id": 2}]}}`,
+	}
+	for name, contents := range bad {
+		s := testStore(t, "keep me")
+		fileName := filepath.Join(t.TempDir(), "snap.json")
+		if err := os.WriteFile(fileName, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Restore(fileName, t.TempDir()); err == nil {
+			t.Errorf("%s: restore succeeded", name)
+		}
+		if got := itemTexts(s.Items(defaultList)); len(got) != 1 {
+			t.Errorf("%s: store changed to %q", name, got)
+		}
+	}
+}
+
+func TestRestoreBringsBackAttachmentFiles(t *testing.T) {
+	s := testStore(t, "a", "b")
+	dir := t.TempDir()
+	os.MkdirAll(s.attachmentDir(), 0700)
+	os.WriteFile(filepath.Join(s.attachmentDir(), "kept"), []byte("hello"), 0600)
+	if err := s.Attach(1, []Attachment{{ID: "kept", Name: "kept.txt", Type: "text/plain"}}); err != nil {
+		t.Fatal(err)
+	}
+	// A snapshot can refer to a file that is already gone
+	if err := s.Attach(2, []Attachment{{ID: "gone", Name: "gone.txt"}}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := s.Snapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Bulk(BulkOp{Action: BulkDelete, IDs: []int{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Restore(snapshot, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := store
+	defer func() { store = saved }()
+	store = s
+	recorder := httptest.NewRecorder()
+	attachmentHandler(recorder, httptest.NewRequest(http.MethodGet, "/attachment?id=kept", nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "hello" {
+		t.Errorf("download after restore gave %d %q", recorder.Code, recorder.Body)
+	}
+	if _, ok := s.FindAttachment("gone"); ok {
+		t.Error("attachment with no file was restored")
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// The greetings for one language. Each is a template
+// that can use {{.Name}}
+type greetingLocale struct {
+	Hello     string
+	Morning   string
+	Afternoon string
+	Evening   string
+}
+
+// The languages we can greet in
+var greetings = map[string]greetingLocale{
+	"en": {
+		Hello:     "Hello {{.Name}}",
+		Morning:   "Good morning {{.Name}}",
+		Afternoon: "Good afternoon {{.Name}}",
+		Evening:   "Good evening {{.Name}}",
+	},
+	"es": {
+		Hello:     "Hola {{.Name}}",
+		Morning:   "Buenos días {{.Name}}",
+		Afternoon: "Buenas tardes {{.Name}}",
+		Evening:   "Buenas noches {{.Name}}",
+	},
+	"fr": {
+		Hello:     "Bonjour {{.Name}}",
+		Morning:   "Bonjour {{.Name}}",
+		Afternoon: "Bon après-midi {{.Name}}",
+		Evening:   "Bonsoir {{.Name}}",
+	},
+}
+
+// Every greeting parsed once, keyed by its text
+var greetingTemplates = parseGreetings(greetings)
+
+// Parse the greetings of every language. A bad one
+// stops the server at startup rather than on a request
+func parseGreetings(locales map[string]greetingLocale) map[string]*texttemplate.Template {
+	parsed := map[string]*texttemplate.Template{}
+	for lang, l := range locales {
+		for _, text := range []string{l.Hello, l.Morning, l.Afternoon, l.Evening} {
+			parsed[text] = texttemplate.Must(texttemplate.New(lang).Parse(text))
+		}
+	}
+	return parsed
+}
+
+// Used when nobody gives their name
+const defaultGreetingName = "Internet"
+
+// Tells the time of day. Tests replace it
+var greetNow = time.Now
+
+// Pick the greeting for an hour of the day. Late at
+// night we just say hello
+func (l greetingLocale) forHour(hour int) string {
+	switch {
+	case hour >= 5 && hour < 12:
+		return l.Morning
+	case hour >= 12 && hour < 18:
+		return l.Afternoon
+	case hour >= 18 && hour < 23:
+		return l.Evening
+	}
+	return l.Hello
+}
+
+// Fill in a greeting for a name in a language
+func greet(lang, name string, now time.Time) (string, error) {
+	if name == "" {
+		name = defaultGreetingName
+	}
+	locale, ok := greetings[lang]
+	if !ok {
+		return "", fmt.Errorf("no greetings in %q", lang)
+	}
+	tmpl := greetingTemplates[locale.forHour(now.Hour())]
+	var greeting strings.Builder
+	err := tmpl.Execute(&greeting, struct{ Name string }{name})
+	return greeting.String(), err
+}
+
+// Pick the supported language the browser wants most
+// from an Accept-Language header like
+// "fr-CH, fr;q=0.9, en;q=0.8". English is the fallback
+func negotiateLanguage(header string) string {
+	type choice struct {
+		lang string
+		q    float64
+	}
+	var choices []choice
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			parsed, err := strconv.ParseFloat(strings.TrimPrefix(params, "q="), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		// Only the base language matters, fr-CH is fr
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if _, ok := greetings[base]; ok && q > 0 {
+			choices = append(choices, choice{base, q})
+		}
+	}
+	sort.SliceStable(choices, func(i, j int) bool {
+		return choices[i].q > choices[j].q
+	})
+	if len(choices) == 0 {
+		return "en"
+	}
+	return choices[0].lang
+}
+
+// Check if the client asked for JSON
+func wantsJSON(request *http.Request) bool {
+	if format := request.FormValue("format"); format != "" {
+		return format == "json"
+	}
+	accept := request.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") &&
+		!strings.Contains(accept, "text/html")
+}
+
+// The page the greeting is shown on
+var greetPage = template.Must(template.New("greet").Parse(
+	`<!DOCTYPE html><html lang="{{.Lang}}"><p>{{.Greeting}}</p></html>`))
+
+// Greets the visitor at /greet/{lang}?name=. Without a
+// language the Accept-Language header picks one
+func greetHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	lang := strings.Trim(strings.TrimPrefix(request.URL.Path, "/greet"), "/")
+	if lang == "" {
+		lang = negotiateLanguage(request.Header.Get("Accept-Language"))
+	}
+	if _, ok := greetings[lang]; !ok {
+		http.NotFound(writer, request)
+		return
+	}
+	name := strings.TrimSpace(request.FormValue("name"))
+	greeting, err := greet(lang, name, greetNow())
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The answer changes with these headers
+	writer.Header().Set("Vary", "Accept, Accept-Language")
+	writer.Header().Set("Content-Language", lang)
+	data := struct {
+		Lang     string `json:"lang"`
+		Name     string `json:"name"`
+		Greeting string `json:"greeting"`
+	}{lang, name, greeting}
+	if wantsJSON(request) {
+		writeJSON(writer, http.StatusOK, data)
+		return
+	}
+	err = greetPage.Execute(writer, data)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// Send the old greeting routes to /greet in their
+// language
+func redirectGreeting(lang string) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		target := "/greet/" + lang
+		if request.URL.RawQuery != "" {
+			target += "?" + request.URL.RawQuery
+		}
+		http.Redirect(writer, request, target, http.StatusMovedPermanently)
+	}
+}
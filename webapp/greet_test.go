@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNegotiateLanguage(t *testing.T) {
+	tests := map[string]string{
+		"":                           "en",
+		"fr-CH, fr;q=0.9, en;q=0.8":  "fr",
+		"de, es;q=0.5, en;q=0.4":     "es",
+		"en;q=0.1, es-MX;q=0.7":      "es",
+		"de":                         "en",
+		"es;q=0, fr;q=bad, en;q=0.2": "en",
+		"FR":                         "fr",
+	}
+	for header, want := range tests {
+		if got := negotiateLanguage(header); got != want {
+			t.Errorf("%q: got %s, want %s", header, got, want)
+		}
+	}
+}
+
+func TestGreetTimeOfDay(t *testing.T) {
+	day := func(hour int) time.Time {
+		return time.Date(2024, 1, 1, hour, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		lang, name string
+		hour       int
+		want       string
+	}{
+		{"en", "Derek", 8, "Good morning Derek"},
+		{"es", "Ana", 13, "Buenas tardes Ana"},
+		{"fr", "", 20, "Bonsoir Internet"},
+		{"en", "", 2, "Hello Internet"},
+	}
+	for _, test := range tests {
+		got, err := greet(test.lang, test.name, day(test.hour))
+		if err != nil || got != test.want {
+			t.Errorf("%s %q at %d: got %q, %v, want %q",
+				test.lang, test.name, test.hour, got, err, test.want)
+		}
+	}
+}
+
+func TestGreetHandler(t *testing.T) {
+	old := greetNow
+	defer func() { greetNow = old }()
+	greetNow = func() time.Time { return time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC) }
+
+	request := httptest.NewRequest(http.MethodGet, "/greet/es?name=Ana", nil)
+	request.Header.Set("Accept", "application/json")
+	recorder := httptest.NewRecorder()
+	greetHandler(recorder, request)
+	var body map[string]string
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body["greeting"] != "Buenos días Ana" {
+		t.Errorf("JSON greeting is %q", body["greeting"])
+	}
+
+	// HTML escapes the name and negotiates the language
+	request = httptest.NewRequest(http.MethodGet, "/greet/?name=<b>Jo</b>", nil)
+	request.Header.Set("Accept-Language", "fr")
+	recorder = httptest.NewRecorder()
+	greetHandler(recorder, request)
+	if got := recorder.Body.String(); !strings.Contains(got, "Bonjour &lt;b&gt;Jo&lt;/b&gt;") {
+		t.Errorf("HTML is %q", got)
+	}
+
+	recorder = httptest.NewRecorder()
+	greetHandler(recorder, httptest.NewRequest(http.MethodGet, "/greet/xx", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("unknown language got status %d", recorder.Code)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// HistoryEntry records one change made to the store.
+// Bulk actions write a single entry listing every
+// item they touched
+type HistoryEntry struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	List   string    `json:"list,omitempty"`
+	Target string    `json:"target,omitempty"`
+	IDs    []int     `json:"ids,omitempty"`
+	Text   string    `json:"text,omitempty"`
+}
+
+// Append an entry to the history file as one line of JSON
+func appendHistory(fileName string, entry HistoryEntry) error {
+	options := os.O_WRONLY | os.O_APPEND | os.O_CREATE
+	file, err := os.OpenFile(fileName, options, os.FileMode(0600))
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Read every entry from the history file in the
+// order they were written
+func readHistory(fileName string) ([]HistoryEntry, error) {
+	file, err := os.Open(fileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// param describes a query, form or path parameter
+type param struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+	Type        string
+}
+
+// route is one entry in the route table. The server
+// registers its handler and /openapi.json describes it
+type route struct {
+	// Path is what the mux matches and DocPath is how
+	// it is documented when they differ, like
+	// /greet/ and /greet/{lang}
+	Path    string
+	DocPath string
+	Methods []string
+	Summary string
+	Tag     string
+	Params  []param
+	// Body is the request content type and Example a
+	// sample request body
+	Body    string
+	Example interface{}
+	// Produces is the response content type
+	Produces string
+	// Auth wraps the handler in requireAuth, so once
+	// users or tokens exist it needs credentials. Routes
+	// tagged admin are also shut until then
+	Auth    bool
+	Handler http.HandlerFunc
+}
+
+// Content types used in the table
+const (
+	typeHTML = "text/html"
+	typeJSON = "application/json"
+	typeForm = "application/x-www-form-urlencoded"
+	typeFile = "multipart/form-data"
+)
+
+// Parameters shared by several routes
+var (
+	listParam = param{Name: "list", In: "query", Type: "string",
+		Description: "name of the list, default when empty"}
+	idParam = param{Name: "id", In: "query", Type: "integer", Required: true,
+		Description: "id of the to do"}
+)
+
+// The route table. It is a function because some of the
+// handlers read the table themselves
+func appRoutes() []route {
+	return []route{
+		{Path: "/greet", Methods: []string{"GET"}, Tag: "greeting",
+			Summary:  "Greet the visitor in the language picked by Accept-Language",
+			Params:   []param{{Name: "name", In: "query", Type: "string", Description: "who to greet"}},
+			Produces: typeHTML, Handler: greetHandler},
+		{Path: "/greet/", DocPath: "/greet/{lang}", Methods: []string{"GET"}, Tag: "greeting",
+			Summary: "Greet the visitor in a language",
+			Params: []param{
+				{Name: "lang", In: "path", Type: "string", Required: true, Description: "en, es or fr"},
+				{Name: "name", In: "query", Type: "string", Description: "who to greet"},
+				{Name: "format", In: "query", Type: "string", Description: "json to get JSON"},
+			},
+			Produces: typeHTML, Handler: greetHandler},
+		// The old greetings now live under /greet
+		{Path: "/hello", Methods: []string{"GET"}, Tag: "greeting",
+			Summary: "Redirect to /greet/en", Handler: redirectGreeting("en")},
+		{Path: "/hola", Methods: []string{"GET"}, Tag: "greeting",
+			Summary: "Redirect to /greet/es", Handler: redirectGreeting("es")},
+		{Path: "/bonjour", Methods: []string{"GET"}, Tag: "greeting",
+			Summary: "Redirect to /greet/fr", Handler: redirectGreeting("fr")},
+
+		{Path: "/interact", Methods: []string{"GET"}, Tag: "pages",
+			Summary: "Show a to do list", Params: []param{listParam},
+			Produces: typeHTML, Auth: true, Handler: interactHandler},
+		{Path: "/new", Methods: []string{"GET"}, Tag: "pages",
+			Summary: "Show the form for adding a to do",
+			Params: []param{listParam,
+				{Name: "parent", In: "query", Type: "integer", Description: "id of the parent for a subtask"}},
+			Produces: typeHTML, Auth: true, Handler: newHandler},
+		{Path: "/create", Methods: []string{"POST"}, Tag: "pages",
+			Summary: "Add a to do with optional attachments and redirect to its list",
+			Body:    typeFile, Auth: true, Handler: createHandler},
+		{Path: "/bulk", Methods: []string{"POST"}, Tag: "pages",
+			Summary: "Apply a bulk action to the checked to dos",
+			Body:    typeForm, Auth: true, Handler: bulkHandler},
+		{Path: "/move", Methods: []string{"POST"}, Tag: "pages",
+			Summary: "Move a to do up or down among its siblings",
+			Params: []param{
+				{Name: "item", In: "query", Type: "integer", Required: true, Description: "id of the to do"},
+				{Name: "direction", In: "query", Type: "string", Description: "up or down"},
+			},
+			Auth: true, Handler: moveHandler},
+		{Path: "/edit", Methods: []string{"GET"}, Tag: "pages",
+			Summary: "Show the form for changing a to do", Params: []param{idParam},
+			Produces: typeHTML, Auth: true, Handler: editHandler},
+		{Path: "/update", Methods: []string{"POST"}, Tag: "pages",
+			Summary: "Change a to do's text and attachments",
+			Body:    typeFile, Auth: true, Handler: updateHandler},
+		{Path: "/attachment", Methods: []string{"GET"}, Tag: "pages",
+			Summary: "Download an attachment",
+			Params: []param{{Name: "id", In: "query", Type: "string", Required: true,
+				Description: "id of the attachment"}},
+			Produces: "application/octet-stream", Auth: true, Handler: attachmentHandler},
+		{Path: "/share", Methods: []string{"GET", "POST"}, Tag: "pages",
+			Summary: "List, create and revoke share links for a list",
+			Params:  []param{listParam}, Body: typeForm,
+			Produces: typeHTML, Auth: true, Handler: shareHandler},
+		{Path: "/shared", Methods: []string{"GET"}, Tag: "pages",
+			Summary: "Show a list through a signed share link",
+			Params: []param{
+				{Name: "id", In: "query", Type: "string", Required: true},
+				{Name: "list", In: "query", Type: "string", Required: true},
+				{Name: "exp", In: "query", Type: "integer", Required: true},
+				{Name: "sig", In: "query", Type: "string", Required: true},
+			},
+			Produces: typeHTML, Handler: sharedHandler},
+
+		{Path: "/api/todos", Methods: []string{"GET"}, Tag: "api",
+			Summary: "Get the to dos in a list", Params: []param{listParam},
+			Produces: typeJSON, Auth: true, Handler: apiTodosHandler},
+		{Path: "/api/bulk", Methods: []string{"POST"}, Tag: "api",
+			Summary: "Apply a bulk action: done, delete, clear-completed or move",
+			Body:    typeJSON, Example: BulkOp{Action: BulkMove, IDs: []int{1, 2}, Target: "work"},
+			Produces: typeJSON, Auth: true, Handler: apiBulkHandler},
+		{Path: "/api/move", Methods: []string{"POST"}, Tag: "api",
+			Summary: "Move a to do up, down or to a position among its siblings",
+			Body:    typeJSON, Example: map[string]interface{}{"id": 3, "direction": "up"},
+			Produces: typeJSON, Auth: true, Handler: apiMoveHandler},
+		{Path: "/api/tokens", Methods: []string{"GET", "POST", "DELETE"}, Tag: "api",
+			Summary: "List, create and revoke your API tokens",
+			Params: []param{{Name: "id", In: "query", Type: "string",
+				Description: "token to revoke with DELETE"}},
+			Body: typeJSON, Example: map[string]string{"name": "backup script", "scope": ScopeRead},
+			Produces: typeJSON, Auth: true, Handler: apiTokensHandler},
+
+		{Path: "/admin/backup", Methods: []string{"GET", "POST"}, Tag: "admin",
+			Summary:  "List snapshots or take a new one",
+			Produces: typeJSON, Auth: true, Handler: adminBackupHandler},
+		{Path: "/admin/stats", Methods: []string{"GET"}, Tag: "admin",
+			Summary: "Charts of to dos created and completed, busiest lists and top tags",
+			Params: []param{{Name: "days", In: "query", Type: "integer",
+				Description: "how many days to chart, 30 by default"}},
+			Produces: typeHTML, Auth: true, Handler: adminStatsHandler},
+		{Path: "/admin/webhooks", Methods: []string{"GET", "POST"}, Tag: "admin",
+			Summary: "Manage webhook subscriptions and see deliveries",
+			Body:    typeForm, Produces: typeHTML, Auth: true, Handler: adminWebhooksHandler},
+
+		{Path: "/openapi.json", Methods: []string{"GET"}, Tag: "docs",
+			Summary: "This document", Produces: typeJSON, Handler: openAPIHandler},
+		{Path: "/docs", Methods: []string{"GET"}, Tag: "docs",
+			Summary: "Read the API document as a web page", Produces: typeHTML, Handler: docsHandler},
+	}
+}
+
+// Register every route in the table
+func registerRoutes(mux *http.ServeMux) {
+	for _, r := range appRoutes() {
+		handler := r.Handler
+		if r.Tag == "admin" {
+			handler = requireAdmin(handler)
+		}
+		if r.Auth {
+			handler = requireAuth(handler)
+		}
+		mux.HandleFunc(r.Path, handler)
+	}
+}
+
+// The parts of an OpenAPI 3 document we use
+type openAPIDoc struct {
+	OpenAPI    string                                `json:"openapi"`
+	Info       openAPIInfo                           `json:"info"`
+	Paths      map[string]map[string]openAPIOp       `json:"paths"`
+	Components map[string]map[string]openAPISecurity `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIOp struct {
+	Summary     string                     `json:"summary"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []openAPIParam             `json:"parameters,omitempty"`
+	RequestBody *openAPIBody               `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+}
+
+type openAPIParam struct {
+	Name        string            `json:"name"`
+	In          string            `json:"in"`
+	Description string            `json:"description,omitempty"`
+	Required    bool              `json:"required"`
+	Schema      map[string]string `json:"schema"`
+}
+
+type openAPIBody struct {
+	Content map[string]openAPIMedia `json:"content"`
+}
+
+type openAPIMedia struct {
+	Example interface{} `json:"example,omitempty"`
+}
+
+type openAPIResponse struct {
+	Description string                  `json:"description"`
+	Content     map[string]openAPIMedia `json:"content,omitempty"`
+}
+
+type openAPISecurity struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+// Build the OpenAPI document from the route table
+func openAPIDocument(routes []route) openAPIDoc {
+	doc := openAPIDoc{
+		OpenAPI: "3.0.3",
+		Info:    openAPIInfo{Title: "To Do List", Version: "1.0.0"},
+		Paths:   map[string]map[string]openAPIOp{},
+		Components: map[string]map[string]openAPISecurity{
+			"securitySchemes": {
+				"bearer": {Type: "http", Scheme: "bearer"},
+				"basic":  {Type: "http", Scheme: "basic"},
+			},
+		},
+	}
+	for _, r := range routes {
+		path := r.Path
+		if r.DocPath != "" {
+			path = r.DocPath
+		}
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]openAPIOp{}
+		}
+		for _, method := range r.Methods {
+			op := openAPIOp{
+				Summary:   r.Summary,
+				Responses: map[string]openAPIResponse{},
+			}
+			if r.Tag != "" {
+				op.Tags = []string{r.Tag}
+			}
+			for _, p := range r.Params {
+				op.Parameters = append(op.Parameters, openAPIParam{
+					Name:        p.Name,
+					In:          p.In,
+					Description: p.Description,
+					// Path parameters are always required
+					Required: p.Required || p.In == "path",
+					Schema:   map[string]string{"type": p.Type},
+				})
+			}
+			if r.Body != "" && method != "GET" && method != "DELETE" {
+				op.RequestBody = &openAPIBody{Content: map[string]openAPIMedia{
+					r.Body: {Example: r.Example},
+				}}
+			}
+			switch {
+			case r.Produces != "":
+				op.Responses["200"] = openAPIResponse{Description: "OK",
+					Content: map[string]openAPIMedia{r.Produces: {}}}
+			case method == "POST":
+				op.Responses["302"] = openAPIResponse{Description: "Redirect back to the page"}
+			default:
+				op.Responses["301"] = openAPIResponse{Description: "Redirect"}
+			}
+			if r.Auth {
+				op.Security = []map[string][]string{{"bearer": {}}, {"basic": {}}}
+				op.Responses["401"] = openAPIResponse{Description: "Missing or bad credentials"}
+			}
+			doc.Paths[path][strings.ToLower(method)] = op
+		}
+	}
+	return doc
+}
+
+// Serves the OpenAPI document
+func openAPIHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	writeJSON(writer, http.StatusOK, openAPIDocument(appRoutes()))
+}
+
+// One operation on the docs page
+type docsEntry struct {
+	Path   string
+	Method string
+	openAPIOp
+}
+
+// Shows the OpenAPI document as a page with no scripts
+func docsHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	doc := openAPIDocument(appRoutes())
+	var entries []docsEntry
+	for path, ops := range doc.Paths {
+		for method, op := range ops {
+			entries = append(entries, docsEntry{path, strings.ToUpper(method), op})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Path != entries[j].Path {
+			return entries[i].Path < entries[j].Path
+		}
+		return entries[i].Method < entries[j].Method
+	})
+
+	// Examples are shown as JSON
+	funcs := template.FuncMap{"json": func(v interface{}) (string, error) {
+		b, err := json.MarshalIndent(v, "", "  ")
+		return string(b), err
+	}}
+	tmpl, err := template.New("docs.html").Funcs(funcs).ParseFiles("docs.html")
+	errorCheck(err)
+	err = tmpl.Execute(writer, struct {
+		Info    openAPIInfo
+		Entries []docsEntry
+	}{doc.Info, entries})
+	if err != nil {
+		log.Println(err)
+	}
+}
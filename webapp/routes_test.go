@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenAPIDocumentCoversRouteTable(t *testing.T) {
+	routes := appRoutes()
+	// Registering panics on duplicate paths
+	registerRoutes(http.NewServeMux())
+
+	recorder := httptest.NewRecorder()
+	openAPIHandler(recorder, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.0.3" {
+		t.Errorf("openapi version is %q", doc.OpenAPI)
+	}
+	operations := 0
+	for _, r := range routes {
+		path := r.Path
+		if r.DocPath != "" {
+			path = r.DocPath
+		}
+		operations += len(r.Methods)
+		if len(doc.Paths[path]) == 0 {
+			t.Errorf("%s is missing from the document", path)
+		}
+	}
+	found := 0
+	for _, ops := range doc.Paths {
+		found += len(ops)
+	}
+	if found != operations {
+		t.Errorf("document has %d operations, table has %d", found, operations)
+	}
+}
+
+func TestOpenAPIOperationDetails(t *testing.T) {
+	doc := openAPIDocument(appRoutes())
+	greet := doc.Paths["/greet/{lang}"]["get"]
+	if len(greet.Parameters) == 0 || greet.Parameters[0].In != "path" || !greet.Parameters[0].Required {
+		t.Errorf("lang parameter is %+v", greet.Parameters)
+	}
+	bulk := doc.Paths["/api/bulk"]["post"]
+	if bulk.RequestBody == nil || bulk.Security == nil {
+		t.Errorf("bulk operation is %+v", bulk)
+	}
+	if _, ok := bulk.Responses["401"]; !ok {
+		t.Error("bulk operation doesn't document 401")
+	}
+}
+
+func TestFormRoutesNeedAuthWhenEnabled(t *testing.T) {
+	testAuth(t, "admin:s3cret")
+	defer testAuth(t, "")
+	mux := http.NewServeMux()
+	registerRoutes(mux)
+	for _, path := range []string{"/interact", "/new", "/create", "/bulk", "/move",
+		"/update", "/edit", "/attachment"} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, nil))
+		if recorder.Code != http.StatusUnauthorized {
+			t.Errorf("%s without credentials: got status %d", path, recorder.Code)
+		}
+	}
+}
+
+func TestAdminRoutesShutWithoutCredentials(t *testing.T) {
+	testAuth(t, "")
+	mux := http.NewServeMux()
+	registerRoutes(mux)
+	for _, path := range []string{"/admin/backup", "/admin/stats", "/admin/webhooks"} {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusForbidden {
+			t.Errorf("%s with auth off: got status %d", path, recorder.Code)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ShareLink gives read-only access to one list until
+// it expires or its owner revokes it
+type ShareLink struct {
+	ID      string    `json:"id"`
+	List    string    `json:"list"`
+	Owner   string    `json:"owner"`
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires"`
+	Revoked bool      `json:"revoked"`
+}
+
+// Shares keeps the share links in a JSON file and
+// signs their URLs with a secret key
+type Shares struct {
+	mu    sync.Mutex
+	path  string
+	key   []byte
+	links []ShareLink
+	now   func() time.Time
+}
+
+var (
+	errBadSignature = errors.New("share link signature is not valid")
+	errExpired      = errors.New("share link has expired")
+	errRevoked      = errors.New("share link has been revoked")
+)
+
+// The share links for the running server
+var shares *Shares
+
+// Read the signing key, creating a random one the
+// first time
+func loadKey(fileName string) ([]byte, error) {
+	key, err := os.ReadFile(fileName)
+	if err == nil {
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	key, err = randomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	return key, os.WriteFile(fileName, key, 0600)
+}
+
+// Load the share links, starting empty if the file is
+// missing
+func OpenShares(fileName string, key []byte) (*Shares, error) {
+	sh := &Shares{path: fileName, key: key, now: time.Now}
+	contents, err := os.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return sh, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(contents, &sh.links)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", fileName, err)
+	}
+	return sh, nil
+}
+
+// Sign the parts of a link that must not change
+func (sh *Shares) sign(id, list string, expires int64) string {
+	mac := hmac.New(sha256.New, sh.key)
+	fmt.Fprintf(mac, "%s\n%s\n%d", id, list, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Return the path that opens a link
+func (sh *Shares) URL(link ShareLink) string {
+	expires := link.Expires.Unix()
+	values := url.Values{}
+	values.Set("id", link.ID)
+	values.Set("list", link.List)
+	values.Set("exp", strconv.FormatInt(expires, 10))
+	values.Set("sig", sh.sign(link.ID, link.List, expires))
+	return "/shared?" + values.Encode()
+}
+
+// Create a link to a list that lasts for ttl
+func (sh *Shares) Create(owner, list string, ttl time.Duration) (ShareLink, error) {
+	if ttl <= 0 {
+		return ShareLink{}, errors.New("share links must expire in the future")
+	}
+	id, err := randomBytes(12)
+	if err != nil {
+		return ShareLink{}, err
+	}
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	now := sh.now()
+	link := ShareLink{
+		ID:      hex.EncodeToString(id),
+		List:    list,
+		Owner:   owner,
+		Created: now,
+		// The URL only carries whole seconds
+		Expires: now.Add(ttl).Truncate(time.Second),
+	}
+	links := append(append([]ShareLink(nil), sh.links...), link)
+	err = writeJSONFile(sh.path, links)
+	if err != nil {
+		return ShareLink{}, err
+	}
+	sh.links = links
+	return link, nil
+}
+
+// Return the links an owner made for a list
+func (sh *Shares) List(owner, list string) []ShareLink {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	var found []ShareLink
+	for _, link := range sh.links {
+		if link.Owner == owner && link.List == list {
+			found = append(found, link)
+		}
+	}
+	return found
+}
+
+// Revoke one of an owner's links
+func (sh *Shares) Revoke(owner, id string) error {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	for i, link := range sh.links {
+		if link.ID == id && link.Owner == owner {
+			links := append([]ShareLink(nil), sh.links...)
+			links[i].Revoked = true
+			err := writeJSONFile(sh.path, links)
+			if err != nil {
+				return err
+			}
+			sh.links = links
+			return nil
+		}
+	}
+	return errNotFound
+}
+
+// Check the query of a shared URL and return the list
+// it opens
+func (sh *Shares) Verify(query url.Values) (string, error) {
+	id := query.Get("id")
+	list := query.Get("list")
+	expires, err := strconv.ParseInt(query.Get("exp"), 10, 64)
+	if err != nil {
+		return "", errBadSignature
+	}
+	// Check the signature first so nothing else about
+	// a forged link is revealed
+	want := sh.sign(id, list, expires)
+	if !hmac.Equal([]byte(want), []byte(query.Get("sig"))) {
+		return "", errBadSignature
+	}
+	if !sh.now().Before(time.Unix(expires, 0)) {
+		return "", errExpired
+	}
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	for _, link := range sh.links {
+		if link.ID == id && link.List == list {
+			if link.Revoked {
+				return "", errRevoked
+			}
+			return list, nil
+		}
+	}
+	return "", errRevoked
+}
+
+// Shows a shared list without any way to change it
+func sharedHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	list, err := shares.Verify(request.URL.Query())
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusForbidden)
+		return
+	}
+	tmpl, err := template.ParseFiles("view.html")
+	errorCheck(err)
+	// Don't let the page leak through the Referer header
+	writer.Header().Set("Referrer-Policy", "no-referrer")
+	err = tmpl.Execute(writer, newToDoList(list, true))
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// What the share page shows
+type sharePage struct {
+	List  string
+	Links []shareLinkView
+}
+
+type shareLinkView struct {
+	ShareLink
+	URL     string
+	Expired bool
+}
+
+// GET lists the share links for a list. POST creates
+// a link or revokes one
+func shareHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	who, _ := principalFrom(request.Context())
+	list := listName(request)
+
+	if request.Method == http.MethodPost {
+		var err error
+		switch request.FormValue("action") {
+		case "create":
+			var ttl time.Duration
+			ttl, err = time.ParseDuration(request.FormValue("ttl"))
+			if err == nil {
+				_, err = shares.Create(who.User, list, ttl)
+			}
+		case "revoke":
+			err = shares.Revoke(who.User, request.FormValue("id"))
+		default:
+			err = errors.New("unknown action")
+		}
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Redirect(writer, request, "/share?list="+url.QueryEscape(list), http.StatusFound)
+		return
+	}
+
+	page := sharePage{List: list}
+	now := time.Now()
+	for _, link := range shares.List(who.User, list) {
+		page.Links = append(page.Links, shareLinkView{
+			ShareLink: link,
+			URL:       shares.URL(link),
+			Expired:   !now.Before(link.Expires),
+		})
+	}
+	tmpl, err := template.ParseFiles("share.html")
+	errorCheck(err)
+	err = tmpl.Execute(writer, page)
+	if err != nil {
+		log.Println(err)
+	}
+}
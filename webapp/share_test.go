@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testShares(t *testing.T) *Shares {
+	t.Helper()
+	sh, err := OpenShares(filepath.Join(t.TempDir(), "shares.json"), []byte("test key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sh
+}
+
+// Pull the query out of a share URL
+func shareQuery(t *testing.T, sh *Shares, link ShareLink) url.Values {
+	t.Helper()
+	u, err := url.Parse(sh.URL(link))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u.Query()
+}
+
+func TestShareLinkVerify(t *testing.T) {
+	sh := testShares(t)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sh.now = func() time.Time { return now }
+
+	link, err := sh.Create("alice", "groceries", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := shareQuery(t, sh, link)
+	if list, err := sh.Verify(query); err != nil || list != "groceries" {
+		t.Fatalf("verify gave %q, %v", list, err)
+	}
+
+	// Changing any signed value breaks the signature
+	for _, field := range []string{"id", "list", "exp", "sig"} {
+		tampered := url.Values{}
+		for k, v := range query {
+			tampered[k] = v
+		}
+		tampered.Set(field, tampered.Get(field)+"1")
+		if _, err := sh.Verify(tampered); err != errBadSignature {
+			t.Errorf("tampered %s: got %v, want errBadSignature", field, err)
+		}
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := sh.Verify(query); err != errExpired {
+		t.Errorf("got %v, want errExpired", err)
+	}
+}
+
+func TestShareLinkRevoke(t *testing.T) {
+	sh := testShares(t)
+	link, err := sh.Create("alice", "groceries", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sh.Revoke("bob", link.ID); err != errNotFound {
+		t.Errorf("bob revoked alice's link: %v", err)
+	}
+	if err := sh.Revoke("alice", link.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sh.Verify(shareQuery(t, sh, link)); err != errRevoked {
+		t.Errorf("got %v, want errRevoked", err)
+	}
+
+	// Revocation survives a restart
+	reopened, err := OpenShares(sh.path, sh.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.Verify(shareQuery(t, reopened, link)); err != errRevoked {
+		t.Errorf("after reopening got %v, want errRevoked", err)
+	}
+}
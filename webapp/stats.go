@@ -0,0 +1,242 @@
+package main
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DayCount is how many to dos were created and
+// completed on one day
+type DayCount struct {
+	Day       time.Time
+	Created   int
+	Completed int
+}
+
+// NameCount is a list or tag and how often it was seen
+type NameCount struct {
+	Name  string
+	Count int
+}
+
+// Stats is what the stats page shows
+type Stats struct {
+	Days           []DayCount
+	TotalCreated   int
+	TotalCompleted int
+	// Average time from creating a to do to completing
+	// it, for the to dos where both were recorded
+	AverageCompletion time.Duration
+	BusiestLists      []NameCount
+	TopTags           []NameCount
+}
+
+// Tags are words starting with # in a to do
+var tagPattern = regexp.MustCompile(`#(\w+)`)
+
+// How many lists and tags the page shows
+const statsTopN = 5
+
+// Work out the stats from the history for the days
+// ending with now
+func computeStats(entries []HistoryEntry, now time.Time, days int) Stats {
+	var stats Stats
+	today := startOfDay(now)
+	first := today.AddDate(0, 0, -(days - 1))
+	perDay := map[time.Time]*DayCount{}
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		stats.Days = append(stats.Days, DayCount{Day: day})
+	}
+	for i := range stats.Days {
+		perDay[stats.Days[i].Day] = &stats.Days[i]
+	}
+
+	created := map[int]time.Time{}
+	lists := map[string]int{}
+	tags := map[string]int{}
+	var totalCompletion time.Duration
+	completions := 0
+	for _, entry := range entries {
+		// Entries read from disk have their own zone
+		day := perDay[startOfDay(entry.Time.In(now.Location()))]
+		if entry.List != "" {
+			lists[entry.List] += len(entry.IDs)
+		}
+		switch entry.Action {
+		// Imported to dos were created then too
+		case "create", "import":
+			for _, id := range entry.IDs {
+				created[id] = entry.Time
+			}
+			stats.TotalCreated += len(entry.IDs)
+			if day != nil {
+				day.Created += len(entry.IDs)
+			}
+			for _, match := range tagPattern.FindAllStringSubmatch(entry.Text, -1) {
+				tags[strings.ToLower(match[1])]++
+			}
+		case "bulk-" + BulkDone:
+			stats.TotalCompleted += len(entry.IDs)
+			if day != nil {
+				day.Completed += len(entry.IDs)
+			}
+			for _, id := range entry.IDs {
+				if start, ok := created[id]; ok {
+					totalCompletion += entry.Time.Sub(start)
+					completions++
+				}
+			}
+		}
+	}
+	if completions > 0 {
+		stats.AverageCompletion = totalCompletion / time.Duration(completions)
+	}
+	stats.BusiestLists = topCounts(lists, statsTopN)
+	stats.TopTags = topCounts(tags, statsTopN)
+	return stats
+}
+
+// Average completion to the nearest minute
+func (s Stats) AverageCompletionText() string {
+	return s.AverageCompletion.Round(time.Minute).String()
+}
+
+// Midnight at the start of a time's day
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// Return the n biggest counts, biggest first and ties
+// in name order
+func topCounts(counts map[string]int, n int) []NameCount {
+	var list []NameCount
+	for name, count := range counts {
+		if count > 0 {
+			list = append(list, NameCount{name, count})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Name < list[j].Name
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// Bar is one bar of an SVG chart in pixels
+type Bar struct {
+	X, Y, Width, Height int
+	Label               string
+	Value               int
+	// Only some labels fit under the bars
+	ShowLabel bool
+}
+
+// Chart is an SVG bar chart drawn on the server
+type Chart struct {
+	Title  string
+	Width  int
+	Height int
+	Color  string
+	Max    int
+	Bars   []Bar
+}
+
+// Chart sizes in pixels
+const (
+	chartHeight = 120
+	chartLabels = 20
+	barWidth    = 16
+	barGap      = 4
+)
+
+// Lay out a bar chart for some labelled values
+func newChart(title, color string, labels []string, values []int) Chart {
+	chart := Chart{
+		Title:  title,
+		Color:  color,
+		Width:  len(values) * (barWidth + barGap),
+		Height: chartHeight + chartLabels,
+	}
+	for _, value := range values {
+		if value > chart.Max {
+			chart.Max = value
+		}
+	}
+	for i, value := range values {
+		height := 0
+		if chart.Max > 0 {
+			height = value * chartHeight / chart.Max
+		}
+		chart.Bars = append(chart.Bars, Bar{
+			X:      i * (barWidth + barGap),
+			Y:      chartHeight - height,
+			Width:  barWidth,
+			Height: height,
+			Label:  labels[i],
+			Value:  value,
+			// Label the first bar and every week after it
+			ShowLabel: i%7 == 0,
+		})
+	}
+	return chart
+}
+
+// What stats.html shows
+type statsPage struct {
+	Stats
+	Charts []Chart
+	// Where the labels go under the bars
+	ChartLabelY int
+}
+
+// Build the charts for the stats page
+func newStatsPage(stats Stats) statsPage {
+	var labels []string
+	var created, completed []int
+	for _, day := range stats.Days {
+		labels = append(labels, day.Day.Format("Jan 2"))
+		created = append(created, day.Created)
+		completed = append(completed, day.Completed)
+	}
+	page := statsPage{Stats: stats, ChartLabelY: chartHeight + chartLabels - 6}
+	page.Charts = []Chart{
+		newChart("Created per day", "#4a90d9", labels, created),
+		newChart("Completed per day", "#5cb85c", labels, completed),
+	}
+	return page
+}
+
+// Shows charts of how the lists have been used,
+// worked out from the history
+func adminStatsHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	days, err := strconv.Atoi(request.FormValue("days"))
+	if err != nil || days < 1 || days > 366 {
+		days = 30
+	}
+	entries, err := store.History()
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	page := newStatsPage(computeStats(entries, time.Now(), days))
+
+	tmpl, err := template.ParseFiles("stats.html")
+	errorCheck(err)
+	err = tmpl.Execute(writer, page)
+	if err != nil {
+		log.Println(err)
+	}
+}
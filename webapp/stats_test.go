@@ -0,0 +1,57 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestComputeStats(t *testing.T) {
+	day := func(d, hour int) time.Time {
+		return time.Date(2024, 3, d, hour, 0, 0, 0, time.UTC)
+	}
+	entries := []HistoryEntry{
+		{Time: day(1, 9), Action: "create", List: "home", IDs: []int{1}, Text: "Clean #house"},
+		{Time: day(1, 10), Action: "create", List: "home", IDs: []int{2}, Text: "Fix #House door #diy"},
+		{Time: day(2, 9), Action: "create", List: "work", IDs: []int{3}, Text: "Report"},
+		{Time: day(2, 11), Action: "bulk-done", List: "home", IDs: []int{1, 2}},
+		{Time: day(3, 9), Action: "bulk-done", List: "work", IDs: []int{3}},
+		{Time: day(3, 10), Action: "bulk-move", List: "home", Target: "work", IDs: []int{1}},
+	}
+	stats := computeStats(entries, day(3, 12).In(time.FixedZone("test", 0)), 3)
+
+	var created, completed []int
+	for _, d := range stats.Days {
+		created = append(created, d.Created)
+		completed = append(completed, d.Completed)
+	}
+	if !reflect.DeepEqual(created, []int{2, 1, 0}) || !reflect.DeepEqual(completed, []int{0, 2, 1}) {
+		t.Errorf("created %v and completed %v per day", created, completed)
+	}
+	// 26h, 25h and 24h to complete
+	if stats.AverageCompletion != 25*time.Hour {
+		t.Errorf("average completion is %v", stats.AverageCompletion)
+	}
+	wantLists := []NameCount{{"home", 5}, {"work", 2}}
+	if !reflect.DeepEqual(stats.BusiestLists, wantLists) {
+		t.Errorf("busiest lists are %v", stats.BusiestLists)
+	}
+	wantTags := []NameCount{{"house", 2}, {"diy", 1}}
+	if !reflect.DeepEqual(stats.TopTags, wantTags) {
+		t.Errorf("top tags are %v", stats.TopTags)
+	}
+}
+
+func TestNewChartScalesBars(t *testing.T) {
+	chart := newChart("test", "#000", []string{"a", "b", "c"}, []int{0, 5, 10})
+	var heights []int
+	for _, bar := range chart.Bars {
+		heights = append(heights, bar.Height)
+		if bar.Y+bar.Height != chartHeight {
+			t.Errorf("bar %s doesn't sit on the axis", bar.Label)
+		}
+	}
+	if !reflect.DeepEqual(heights, []int{0, chartHeight / 2, chartHeight}) {
+		t.Errorf("bar heights are %v", heights)
+	}
+}
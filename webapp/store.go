@@ -0,0 +1,553 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The list new to dos go in when none is given
+const defaultList = "default"
+
+// Item is a single to do
+type Item struct {
+	ID        int        `json:"id"`
+	Parent    int        `json:"parent,omitempty"`
+	Text      string     `json:"text"`
+	Done      bool       `json:"done"`
+	Created   time.Time  `json:"created"`
+	Completed *time.Time `json:"completed,omitempty"`
+	// Files uploaded to the to do
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// storeData is everything that is saved to disk
+type storeData struct {
+	NextID int               `json:"next_id"`
+	Lists  map[string][]Item `json:"lists"`
+}
+
+// Store keeps the to do lists in memory and writes
+// them to a JSON file in the data directory after
+// every change. A mutex makes each change atomic
+type Store struct {
+	mu          sync.Mutex
+	dir         string
+	data        storeData
+	now         func() time.Time
+	historyFile string
+	listeners   []func(HistoryEntry)
+}
+
+// Bulk actions that can be applied to many items at once
+const (
+	BulkDone           = "done"
+	BulkDelete         = "delete"
+	BulkClearCompleted = "clear-completed"
+	BulkMove           = "move"
+)
+
+// BulkOp describes one bulk action. IDs selects the
+// items for done, delete and move. List is the list
+// cleared by clear-completed and Target is the list
+// items are moved to
+type BulkOp struct {
+	Action string `json:"action"`
+	IDs    []int  `json:"ids"`
+	List   string `json:"list"`
+	Target string `json:"target"`
+}
+
+var (
+	errNoItems = errors.New("no items selected")
+	errMethod  = errors.New("method not allowed")
+	// A change returns this when it has nothing to do,
+	// so nothing is saved or recorded
+	errUnchanged = errors.New("nothing changed")
+)
+
+// Open the store in a directory. The first time it is
+// opened the lines of todos.txt are imported into the
+// default list. The import is saved and recorded
+// straight away so a restart doesn't import them again
+// with new creation times
+func OpenStore(dir string) (*Store, error) {
+	s := &Store{
+		dir:         dir,
+		now:         time.Now,
+		historyFile: filepath.Join(dir, "history.log"),
+	}
+	contents, err := os.ReadFile(s.path())
+	if os.IsNotExist(err) {
+		s.data = storeData{NextID: 1, Lists: map[string][]Item{}}
+		lines := getStrings(filepath.Join(dir, "todos.txt"))
+		if len(lines) == 0 {
+			return s, nil
+		}
+		entry := HistoryEntry{Time: s.now(), Action: "import", List: defaultList,
+			Text: strings.Join(lines, "\n")}
+		for _, line := range lines {
+			item := s.data.newItem(line, entry.Time)
+			s.data.Lists[defaultList] = append(s.data.Lists[defaultList], item)
+			entry.IDs = append(entry.IDs, item.ID)
+		}
+		if err := writeJSONFile(s.path(), s.data); err != nil {
+			return nil, err
+		}
+		if err := appendHistory(s.historyFile, entry); err != nil {
+			log.Println("history:", err)
+		}
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(contents, &s.data); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", s.path(), err)
+	}
+	if s.data.Lists == nil {
+		s.data.Lists = map[string][]Item{}
+	}
+	return s, nil
+}
+
+// The JSON file the store is saved in
+func (s *Store) path() string {
+	return filepath.Join(s.dir, "todos.json")
+}
+
+// Create an item with the next free id
+func (d *storeData) newItem(text string, created time.Time) Item {
+	item := Item{ID: d.NextID, Text: text, Created: created}
+	d.NextID++
+	return item
+}
+
+// Return the names of every list in sorted order
+func (s *Store) ListNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.listNames()
+}
+
+// Return a copy of the items in a list
+func (s *Store) Items(list string) []Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Item(nil), s.data.Lists[list]...)
+}
+
+// Find an item by id and return the list it is in
+func (s *Store) Find(id int) (Item, string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list, index, ok := s.data.find(id)
+	if !ok {
+		return Item{}, "", false
+	}
+	return s.data.Lists[list][index], list, true
+}
+
+// Add a to do to the end of a list
+func (s *Store) Add(list, text string) (Item, error) {
+	return s.Create(list, 0, text, nil)
+}
+
+// Add a to do with its attachments as one change, so
+// it is recorded once and listeners see the files.
+// A parent above 0 makes it a subtask, which goes in
+// the same list as its parent
+func (s *Store) Create(list string, parent int, text string, attachments []Attachment) (Item, error) {
+	var item Item
+	err := s.update(func(data *storeData) (HistoryEntry, error) {
+		if parent > 0 {
+			parentList, _, ok := data.find(parent)
+			if !ok {
+				return HistoryEntry{}, errNotFound
+			}
+			list = parentList
+		}
+		item = data.newItem(text, s.now())
+		item.Parent = parent
+		item.Attachments = attachments
+		data.Lists[list] = append(data.Lists[list], item)
+		return HistoryEntry{Action: "create", List: list,
+			IDs: []int{item.ID}, Text: text}, nil
+	})
+	return item, err
+}
+
+// Apply a bulk action as one change to the store. It
+// returns the number of items that were changed.
+// Deleting, clearing or moving a to do takes its
+// subtasks with it. The files attached to deleted
+// to dos are removed once the change is saved
+func (s *Store) Bulk(op BulkOp) (int, error) {
+	var changed []int
+	var removed []Attachment
+	err := s.update(func(data *storeData) (HistoryEntry, error) {
+		selected := map[int]bool{}
+		for _, id := range op.IDs {
+			selected[id] = true
+		}
+		switch op.Action {
+		case BulkDone, BulkDelete, BulkMove:
+			if len(selected) == 0 {
+				return HistoryEntry{}, errNoItems
+			}
+		case BulkClearCompleted:
+			for _, item := range data.Lists[op.List] {
+				if item.Done {
+					selected[item.ID] = true
+				}
+			}
+		default:
+			return HistoryEntry{}, fmt.Errorf("unknown bulk action %q", op.Action)
+		}
+		if op.Action == BulkMove && op.Target == "" {
+			return HistoryEntry{}, errors.New("no target list given")
+		}
+		if op.Action != BulkDone {
+			selected = data.withDescendants(selected)
+		}
+
+		// Go through the lists in a fixed order so ties
+		// in the moved items always land the same way
+		var moved []Item
+		for _, name := range data.listNames() {
+			var kept []Item
+			for _, item := range data.Lists[name] {
+				switch {
+				case !selected[item.ID]:
+				case op.Action == BulkDone:
+					if !item.Done {
+						now := s.now()
+						item.Done = true
+						item.Completed = &now
+						changed = append(changed, item.ID)
+					}
+				case op.Action == BulkMove:
+					if name != op.Target {
+						changed = append(changed, item.ID)
+						// A subtask moved without its parent
+						// becomes a top level to do
+						if !selected[item.Parent] {
+							item.Parent = 0
+						}
+						moved = append(moved, item)
+						continue
+					}
+				default:
+					changed = append(changed, item.ID)
+					removed = append(removed, item.Attachments...)
+					continue
+				}
+				kept = append(kept, item)
+			}
+			data.Lists[name] = kept
+		}
+		if len(moved) > 0 {
+			sortBySelection(moved, op.IDs)
+			data.Lists[op.Target] = append(data.Lists[op.Target], moved...)
+		}
+		if len(changed) == 0 {
+			return HistoryEntry{}, errUnchanged
+		}
+		sort.Ints(changed)
+		return HistoryEntry{Action: "bulk-" + op.Action, List: op.List,
+			Target: op.Target, IDs: changed}, nil
+	})
+	if err == errUnchanged {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	removeAttachments(s.attachmentDir(), removed)
+	return len(changed), nil
+}
+
+// Attach files to a to do
+func (s *Store) Attach(id int, attachments []Attachment) error {
+	return s.Update(id, "", attachments, nil)
+}
+
+// Change a to do's text, add attachments and remove
+// the attachments with the given IDs. Empty text
+// keeps the old text
+func (s *Store) Update(id int, text string, add []Attachment, remove []string) error {
+	var removed []Attachment
+	err := s.update(func(data *storeData) (HistoryEntry, error) {
+		list, index, ok := data.find(id)
+		if !ok {
+			return HistoryEntry{}, errNotFound
+		}
+		item := data.Lists[list][index]
+		if text != "" {
+			item.Text = text
+		}
+		dropped := map[string]bool{}
+		for _, attachmentID := range remove {
+			dropped[attachmentID] = true
+		}
+		var kept []Attachment
+		for _, attachment := range item.Attachments {
+			if dropped[attachment.ID] {
+				removed = append(removed, attachment)
+			} else {
+				kept = append(kept, attachment)
+			}
+		}
+		item.Attachments = append(kept, add...)
+		data.Lists[list][index] = item
+		return HistoryEntry{Action: "update", List: list, IDs: []int{id},
+			Text: item.Text}, nil
+	})
+	if err != nil {
+		return err
+	}
+	removeAttachments(s.attachmentDir(), removed)
+	return nil
+}
+
+// Return the list names in sorted order
+func (d *storeData) listNames() []string {
+	names := []string{}
+	for name := range d.Lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Add the subtasks of every selected item, and their
+// subtasks, to the selection
+func (d *storeData) withDescendants(selected map[int]bool) map[int]bool {
+	all := map[int]bool{}
+	for id := range selected {
+		all[id] = true
+	}
+	for grew := true; grew; {
+		grew = false
+		for _, items := range d.Lists {
+			for _, item := range items {
+				if item.Parent != 0 && all[item.Parent] && !all[item.ID] {
+					all[item.ID] = true
+					grew = true
+				}
+			}
+		}
+	}
+	return all
+}
+
+// Find an item by id and return the list it is in
+// and its index
+func (d *storeData) find(id int) (string, int, bool) {
+	for name, items := range d.Lists {
+		for i, item := range items {
+			if item.ID == id {
+				return name, i, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// Add a subtask under a to do. It goes in the same
+// list as its parent
+func (s *Store) AddSubtask(parent int, text string) (Item, error) {
+	return s.Create("", parent, text, nil)
+}
+
+// Move a to do to a position among its siblings, the
+// items in the same list with the same parent. The
+// position is clamped to the first or last place
+func (s *Store) Move(id, position int) error {
+	return s.update(func(data *storeData) (HistoryEntry, error) {
+		list, index, ok := data.find(id)
+		if !ok {
+			return HistoryEntry{}, errNotFound
+		}
+		items := data.Lists[list]
+		parent := items[index].Parent
+
+		// Where the siblings sit in the list
+		var slots []int
+		from := 0
+		for i, item := range items {
+			if item.Parent == parent {
+				if item.ID == id {
+					from = len(slots)
+				}
+				slots = append(slots, i)
+			}
+		}
+		if position < 0 {
+			position = 0
+		}
+		if position > len(slots)-1 {
+			position = len(slots) - 1
+		}
+
+		// Reorder the siblings and put them back in the
+		// same slots so other items don't move
+		siblings := make([]Item, 0, len(slots))
+		for _, slot := range slots {
+			siblings = append(siblings, items[slot])
+		}
+		moving := siblings[from]
+		siblings = append(siblings[:from], siblings[from+1:]...)
+		siblings = append(siblings[:position],
+			append([]Item{moving}, siblings[position:]...)...)
+		for i, slot := range slots {
+			items[slot] = siblings[i]
+		}
+		return HistoryEntry{Action: "move", List: list, IDs: []int{id}}, nil
+	})
+}
+
+// Return the position of a to do among its siblings
+func (s *Store) Position(id int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list, index, ok := s.data.find(id)
+	if !ok {
+		return 0, errNotFound
+	}
+	items := s.data.Lists[list]
+	position := 0
+	for _, item := range items[:index] {
+		if item.Parent == items[index].Parent {
+			position++
+		}
+	}
+	return position, nil
+}
+
+// Register a function that is called after every
+// change with the history entry it recorded
+func (s *Store) OnChange(listener func(HistoryEntry)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+// Keep moved items in the order they were selected.
+// Subtasks that came along with their parent stay
+// right after it in their old order
+func sortBySelection(moved []Item, ids []int) {
+	order := map[int]int{}
+	for i, id := range ids {
+		if _, ok := order[id]; !ok {
+			order[id] = i
+		}
+	}
+	parent := map[int]int{}
+	for _, item := range moved {
+		parent[item.ID] = item.Parent
+	}
+	// A subtask sorts with the selected to do it hangs
+	// from. A chain can't be longer than the items, so
+	// a bad parent link can't loop forever
+	rank := func(id int) int {
+		for steps := 0; steps <= len(moved); steps++ {
+			if i, ok := order[id]; ok {
+				return i
+			}
+			next, ok := parent[id]
+			if !ok {
+				break
+			}
+			id = next
+		}
+		return len(ids)
+	}
+	sort.SliceStable(moved, func(i, j int) bool {
+		return rank(moved[i].ID) < rank(moved[j].ID)
+	})
+}
+
+// Run a change against a copy of the data. The copy
+// only replaces the real data once it has been saved
+// so a failed change leaves the store untouched.
+// The change returns the history entry to record
+func (s *Store) update(change func(data *storeData) (HistoryEntry, error)) error {
+	entry, listeners, err := s.apply(change)
+	if err != nil {
+		return err
+	}
+	// Listeners run without the lock so they can read
+	// the store
+	for _, listener := range listeners {
+		listener(entry)
+	}
+	return nil
+}
+
+// Apply a change and record it while holding the lock
+func (s *Store) apply(change func(data *storeData) (HistoryEntry, error)) (HistoryEntry, []func(HistoryEntry), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data := s.data.clone()
+	entry, err := change(&data)
+	if err != nil {
+		return entry, nil, err
+	}
+	if err := writeJSONFile(s.path(), data); err != nil {
+		return entry, nil, err
+	}
+	s.data = data
+
+	// The change is already saved, so a history file
+	// that can't be written is logged rather than
+	// failing it
+	entry.Time = s.now()
+	if err := appendHistory(s.historyFile, entry); err != nil {
+		log.Println("history:", err)
+	}
+	return entry, s.listeners, nil
+}
+
+// Make a deep copy so changes can be thrown away
+func (d storeData) clone() storeData {
+	c := storeData{NextID: d.NextID, Lists: map[string][]Item{}}
+	for name, items := range d.Lists {
+		c.Lists[name] = append([]Item(nil), items...)
+		// Attachment slices are shared so copy them too
+		for i := range c.Lists[name] {
+			c.Lists[name][i].Attachments = append([]Attachment(nil),
+				c.Lists[name][i].Attachments...)
+		}
+	}
+	return c
+}
+
+// Write a value to a temporary file and rename it
+// over the old one so a crash never leaves half a file
+func writeJSONFile(fileName string, v interface{}) error {
+	contents, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := fileName + ".tmp"
+	if err := os.WriteFile(tmp, contents, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fileName)
+}
+
+// Return every history entry in the order it happened
+func (s *Store) History() ([]HistoryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return readHistory(s.historyFile)
+}
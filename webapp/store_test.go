@@ -0,0 +1,209 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Open a store in a fresh directory with a few to dos
+func testStore(t *testing.T, todos ...string) *Store {
+	t.Helper()
+	s, err := OpenStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, todo := range todos {
+		if _, err := s.Add(defaultList, todo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func itemTexts(items []Item) []string {
+	var texts []string
+	for _, item := range items {
+		texts = append(texts, item.Text)
+	}
+	return texts
+}
+
+func TestOpenStoreImportsTodosTxt(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "todos.txt"),
+		[]byte("Clean Room\r\nWash Car\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := itemTexts(s.Items(defaultList))
+	if want := []string{"Clean Room", "Wash Car"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Opening again reads the saved import
+	reopened, err := OpenStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again := reopened.Items(defaultList)
+	if len(again) != 2 || !again[0].Created.Equal(s.Items(defaultList)[0].Created) {
+		t.Errorf("reopened store has %+v", again)
+	}
+	history, _ := reopened.History()
+	if len(history) != 1 || history[0].Action != "import" || !reflect.DeepEqual(history[0].IDs, []int{1, 2}) {
+		t.Errorf("history is %+v", history)
+	}
+}
+
+func TestBulk(t *testing.T) {
+	s := testStore(t, "a", "b", "c", "d")
+
+	if n, err := s.Bulk(BulkOp{Action: BulkDone, IDs: []int{1, 3}}); err != nil || n != 2 {
+		t.Fatalf("done: changed %d, %v", n, err)
+	}
+	if n, err := s.Bulk(BulkOp{Action: BulkMove, IDs: []int{4, 2}, Target: "work"}); err != nil || n != 2 {
+		t.Fatalf("move: changed %d, %v", n, err)
+	}
+	if got := itemTexts(s.Items("work")); !reflect.DeepEqual(got, []string{"d", "b"}) {
+		t.Errorf("work list is %q", got)
+	}
+	if n, err := s.Bulk(BulkOp{Action: BulkClearCompleted, List: defaultList}); err != nil || n != 2 {
+		t.Fatalf("clear completed: changed %d, %v", n, err)
+	}
+	if got := s.Items(defaultList); len(got) != 0 {
+		t.Errorf("default list still has %v", got)
+	}
+	if n, err := s.Bulk(BulkOp{Action: BulkDelete, IDs: []int{2}}); err != nil || n != 1 {
+		t.Fatalf("delete: changed %d, %v", n, err)
+	}
+
+	// Each action is recorded once after the four creates
+	history, err := s.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, entry := range history[4:] {
+		actions = append(actions, entry.Action)
+	}
+	want := []string{"bulk-done", "bulk-move", "bulk-clear-completed", "bulk-delete"}
+	if !reflect.DeepEqual(actions, want) {
+		t.Errorf("history is %q, want %q", actions, want)
+	}
+	if got := history[5].IDs; !reflect.DeepEqual(got, []int{2, 4}) {
+		t.Errorf("move recorded ids %v", got)
+	}
+}
+
+func TestSortBySelectionStopsOnParentCycle(t *testing.T) {
+	// 2 and 3 point at each other and neither is selected
+	moved := []Item{{ID: 2, Parent: 3}, {ID: 3, Parent: 2}, {ID: 1}}
+	sortBySelection(moved, []int{1})
+	if moved[0].ID != 1 {
+		t.Errorf("got order %v", moved)
+	}
+}
+
+func TestBulkErrorLeavesStoreUntouched(t *testing.T) {
+	s := testStore(t, "a")
+	if _, err := s.Bulk(BulkOp{Action: "explode", IDs: []int{1}}); err == nil {
+		t.Fatal("expected an error for an unknown action")
+	}
+	if _, err := s.Bulk(BulkOp{Action: BulkDone}); err != errNoItems {
+		t.Fatalf("got %v, want errNoItems", err)
+	}
+	// Ids that don't exist change nothing and aren't recorded
+	if n, err := s.Bulk(BulkOp{Action: BulkDelete, IDs: []int{42}}); n != 0 || err != nil {
+		t.Fatalf("missing ids: changed %d, %v", n, err)
+	}
+	if got := s.Items(defaultList); len(got) != 1 || got[0].Done {
+		t.Errorf("store changed to %v", got)
+	}
+	history, _ := s.History()
+	if len(history) != 1 {
+		t.Errorf("got %d history entries, want 1", len(history))
+	}
+}
+
+func TestHistoryErrorKeepsChange(t *testing.T) {
+	s := testStore(t)
+	// A directory can't be appended to
+	s.historyFile = t.TempDir()
+	notified := 0
+	s.OnChange(func(HistoryEntry) { notified++ })
+	if _, err := s.Add(defaultList, "a"); err != nil {
+		t.Fatalf("saved change returned %v", err)
+	}
+	if got := s.Items(defaultList); len(got) != 1 || notified != 1 {
+		t.Errorf("got items %v and %d notifications", got, notified)
+	}
+}
+
+func TestSubtasksAndMove(t *testing.T) {
+	s := testStore(t, "a", "b", "c")
+	sub1, err := s.AddSubtask(1, "a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddSubtask(1, "a2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddSubtask(sub1.ID, "a1x"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddSubtask(99, "orphan"); err != errNotFound {
+		t.Errorf("got %v, want errNotFound", err)
+	}
+
+	// Moving c to the top leaves the subtasks alone
+	if err := s.Move(3, 0); err != nil {
+		t.Fatal(err)
+	}
+	// Moving a2 up swaps it with a1
+	if err := s.Move(5, -5); err != nil {
+		t.Fatal(err)
+	}
+	got := itemTexts(s.Items(defaultList))
+	want := []string{"c", "a", "b", "a2", "a1", "a1x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("order is %q, want %q", got, want)
+	}
+	if position, _ := s.Position(1); position != 1 {
+		t.Errorf("a is at %d, want 1", position)
+	}
+
+	if _, err := s.Bulk(BulkOp{Action: BulkDone, IDs: []int{5}}); err != nil {
+		t.Fatal(err)
+	}
+	tree := buildTree(s.Items(defaultList))
+	if got := tree[1].Progress(); got != "1/2 done" {
+		t.Errorf("progress is %q", got)
+	}
+
+	// Deleting a parent deletes its subtasks too
+	if n, err := s.Bulk(BulkOp{Action: BulkDelete, IDs: []int{1}}); err != nil || n != 4 {
+		t.Fatalf("delete changed %d, %v", n, err)
+	}
+	if got := itemTexts(s.Items(defaultList)); !reflect.DeepEqual(got, []string{"c", "b"}) {
+		t.Errorf("left %q", got)
+	}
+}
+
+func TestMovingSubtaskToAnotherList(t *testing.T) {
+	s := testStore(t, "a")
+	if _, err := s.AddSubtask(1, "a1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Bulk(BulkOp{Action: BulkMove, IDs: []int{2}, Target: "work"}); err != nil {
+		t.Fatal(err)
+	}
+	if item, list, _ := s.Find(2); list != "work" || item.Parent != 0 {
+		t.Errorf("a1 is in %q with parent %d", list, item.Parent)
+	}
+}
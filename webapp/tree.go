@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// ToDoNode is a to do with its subtasks for the page
+type ToDoNode struct {
+	Item
+	Subtasks []*ToDoNode
+	// How many direct subtasks are done
+	DoneCount int
+}
+
+// Shows progress like "2/5 done" for a parent
+func (n *ToDoNode) Progress() string {
+	return strconv.Itoa(n.DoneCount) + "/" + strconv.Itoa(len(n.Subtasks)) + " done"
+}
+
+// Arrange a list's items under their parents keeping
+// the stored order of siblings
+func buildTree(items []Item) []*ToDoNode {
+	nodes := map[int]*ToDoNode{}
+	for _, item := range items {
+		nodes[item.ID] = &ToDoNode{Item: item}
+	}
+	var roots []*ToDoNode
+	for _, item := range items {
+		node := nodes[item.ID]
+		parent, ok := nodes[item.Parent]
+		if item.Parent == 0 || !ok {
+			roots = append(roots, node)
+			continue
+		}
+		parent.Subtasks = append(parent.Subtasks, node)
+		if item.Done {
+			parent.DoneCount++
+		}
+	}
+	return roots
+}
+
+// Collect what view.html shows for a list
+func newToDoList(list string, readOnly bool) ToDoList {
+	todoVals := store.Items(list)
+	todos := ToDoList{
+		ToDoCount: len(todoVals),
+		ToDos:     todoVals,
+		Tree:      buildTree(todoVals),
+		List:      list,
+		ReadOnly:  readOnly,
+	}
+	if !readOnly {
+		todos.Lists = store.ListNames()
+	}
+	return todos
+}
+
+// Work out where a to do should move to from a
+// direction of up or down or a position
+func movePosition(id int, direction, position string) (int, error) {
+	current, err := store.Position(id)
+	if err != nil {
+		return 0, err
+	}
+	switch direction {
+	case "up":
+		return current - 1, nil
+	case "down":
+		return current + 1, nil
+	}
+	return strconv.Atoi(position)
+}
+
+// Moves a to do up or down from the list page. The
+// buttons sit inside the bulk form, which already
+// sends id for each checked box, so the to do to move
+// is passed as item
+func moveHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	if request.Method != http.MethodPost {
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, err := strconv.Atoi(request.FormValue("item"))
+	if err != nil {
+		http.Error(writer, "bad id", http.StatusBadRequest)
+		return
+	}
+	position, err := movePosition(id, request.FormValue("direction"),
+		request.FormValue("position"))
+	if err == nil {
+		err = store.Move(id, position)
+	}
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_, list, _ := store.Find(id)
+	http.Redirect(writer, request, listURL(list), http.StatusFound)
+}
+
+// Moves a to do from JSON like {"id": 3, "direction": "up"}
+// or {"id": 3, "position": 0}
+func apiMoveHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	if request.Method != http.MethodPost {
+		writeJSONError(writer, http.StatusMethodNotAllowed, errMethod)
+		return
+	}
+	var body struct {
+		ID        int    `json:"id"`
+		Direction string `json:"direction"`
+		Position  *int   `json:"position"`
+	}
+	err := json.NewDecoder(request.Body).Decode(&body)
+	if err != nil {
+		writeJSONError(writer, http.StatusBadRequest, err)
+		return
+	}
+	position := ""
+	if body.Position != nil {
+		position = strconv.Itoa(*body.Position)
+	}
+	to, err := movePosition(body.ID, body.Direction, position)
+	if err == errNotFound {
+		writeJSONError(writer, http.StatusNotFound, err)
+		return
+	}
+	if err == nil {
+		err = store.Move(body.ID, to)
+	}
+	if err != nil {
+		writeJSONError(writer, http.StatusBadRequest, err)
+		return
+	}
+	item, list, _ := store.Find(body.ID)
+	to, _ = store.Position(body.ID)
+	writeJSON(writer, http.StatusOK, map[string]interface{}{
+		"todo":     item,
+		"list":     list,
+		"position": to,
+	})
+}
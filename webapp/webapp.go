@@ -4,18 +4,31 @@ package main
 // server requests
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"html/template"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type ToDoList struct {
 	ToDoCount int
-	ToDos     []string
+	ToDos     []Item
+	Tree      []*ToDoNode
+	List      string
+	Lists     []string
+	ReadOnly  bool
 }
 
+// The store holding every to do list
+var store *Store
+
 func errorCheck(err error) {
 	// Handle errors
 	if err != nil {
@@ -23,49 +36,19 @@ func errorCheck(err error) {
 	}
 }
 
-// The writer allows us to write to the browser
-// Create a message and add it to the
-// response that displays in the browser
-func write(writer http.ResponseWriter, msg string) {
-	// Perform type conversion to bytes
-	_, err := writer.Write([]byte(msg))
-	errorCheck(err)
-}
-
-// request is the request from the browser
-func englishHandler(writer http.ResponseWriter,
-	request *http.Request) {
-	write(writer, "Hello Internet")
-}
-
-func spanishHandler(writer http.ResponseWriter,
-	request *http.Request) {
-	write(writer, "Hola Internet")
-}
-
-func frenchHandler(writer http.ResponseWriter,
-	request *http.Request) {
-	write(writer, "Bonjour Internet")
-}
-
 func interactHandler(writer http.ResponseWriter,
 	request *http.Request) {
 
-	// Get our text from the file
-	todoVals := getStrings("todos.txt")
+	// Create a todo list with the number
+	// of to dos in the requested list
+	todos := newToDoList(listName(request), false)
 
 	// Print to the terminal
-	fmt.Printf("%#v\n", todoVals)
+	fmt.Printf("%#v\n", todos.ToDos)
 	// Create a template using the html
 	tmpl, err := template.ParseFiles("view.html")
 	errorCheck(err)
 
-	// Create a todo list with the number
-	todos := ToDoList{
-		ToDoCount: len(todoVals),
-		ToDos:     todoVals,
-	}
-
 	// Write the template to the ResponseWriter
 	// Pass the todo struct data
 	err = tmpl.Execute(writer, todos)
@@ -102,43 +85,207 @@ func newHandler(writer http.ResponseWriter,
 	tmpl, err := template.ParseFiles("new.html")
 	errorCheck(err)
 
-	err = tmpl.Execute(writer, nil)
+	// Pass the list so the new to do lands in it
+	// and the parent if it is a subtask
+	page := newPage{List: listName(request)}
+	page.Parent, _ = strconv.Atoi(request.FormValue("parent"))
+	if item, list, ok := store.Find(page.Parent); ok {
+		page.ParentText = item.Text
+		page.List = list
+	}
+	err = tmpl.Execute(writer, page)
+}
+
+// What new.html shows
+type newPage struct {
+	List       string
+	Parent     int
+	ParentText string
 }
 
 func createHandler(writer http.ResponseWriter,
 	request *http.Request) {
+	// Fails if the form is bigger than the body limit
+	err := parseUploadForm(request)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
 	todo := request.FormValue("todo")
-	// Define options for working with the file
-	options := os.O_WRONLY | os.O_APPEND | os.O_CREATE
-	// Open file with options and permissions
-	file, err := os.OpenFile("todos.txt", options, os.FileMode(0600))
-	errorCheck(err)
-	// Append new text to file
-	_, err = fmt.Fprintln(file, todo)
-	errorCheck(err)
-	// Close file
-	err = file.Close()
-	errorCheck(err)
+	list := listName(request)
+	// Check and save any attached files first
+	attachments, err := saveUploads(store.attachmentDir(), request)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Save the new to do and its files in one change,
+	// under its parent if it is a subtask
+	parent, _ := strconv.Atoi(request.FormValue("parent"))
+	_, err = store.Create(list, parent, todo, attachments)
+	if err != nil {
+		removeAttachments(store.attachmentDir(), attachments)
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	// Redirect to defined page while passing
 	// ResponseWriter, original request,
 	// and a successful request message
-	http.Redirect(writer, request, "/interact", http.StatusFound)
+	http.Redirect(writer, request, listURL(list), http.StatusFound)
+}
+
+// Applies the bulk action picked on the list page
+// to every checked to do
+func bulkHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	if request.Method != http.MethodPost {
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	err := request.ParseForm()
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	list := listName(request)
+	op := BulkOp{
+		Action: request.FormValue("action"),
+		List:   list,
+		Target: request.FormValue("target"),
+	}
+	// Each checked box sends its id
+	for _, val := range request.Form["id"] {
+		id, err := strconv.Atoi(val)
+		if err != nil {
+			http.Error(writer, "bad id "+val, http.StatusBadRequest)
+			return
+		}
+		op.IDs = append(op.IDs, id)
+	}
+	_, err = store.Bulk(op)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(writer, request, listURL(list), http.StatusFound)
+}
+
+// Get the list named in the request or the default
+func listName(request *http.Request) string {
+	list := request.FormValue("list")
+	if list == "" {
+		return defaultList
+	}
+	return list
+}
+
+// The page that shows a list
+func listURL(list string) string {
+	return "/interact?list=" + url.QueryEscape(list)
 }
 
 func main() {
+	// The directory the to dos are saved in
+	dataDir := flag.String("data", ".", "directory to store to dos in")
+	// Snapshots of the to dos are taken on a timer
+	flag.StringVar(&backups.dir, "backup-dir", "",
+		"directory for snapshots (default <data>/backups)")
+	flag.DurationVar(&backups.interval, "backup-interval", time.Hour,
+		"time between snapshots, 0 turns them off")
+	flag.IntVar(&backups.keep, "backup-keep", 24,
+		"number of snapshots to keep, 0 keeps all")
+	restore := flag.String("restore", "",
+		"check a snapshot, load it and exit (stop the server first)")
+	// API tokens can be minted from the command line
+	newToken := flag.String("new-token", "",
+		"create an API token for a user, print it and exit")
+	tokenScope := flag.String("token-scope", ScopeWrite,
+		"scope of the token made by -new-token (read or write)")
+	// Limits and HTTPS for the server
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	maxBody := flag.Int64("max-body", 1<<20, "largest request body in bytes")
+	useTLS := flag.Bool("tls", false,
+		"serve HTTPS, creating a self-signed certificate if needed")
+	certFile := flag.String("cert", "", "TLS certificate (default <data>/cert.pem)")
+	keyFile := flag.String("key", "", "TLS key (default <data>/key.pem)")
+	// What can be attached to a to do
+	flag.Int64Var(&uploads.maxSize, "max-upload", uploads.maxSize,
+		"largest attachment in bytes")
+	allowedTypes := flag.String("allowed-types", strings.Join(uploads.allowed, ","),
+		"comma separated media types that can be attached, like image/*")
+	flag.Parse()
+	uploads.allowed = strings.Split(*allowedTypes, ",")
+	if backups.dir == "" {
+		backups.dir = filepath.Join(*dataDir, "backups")
+	}
+	if *certFile == "" {
+		*certFile = filepath.Join(*dataDir, "cert.pem")
+	}
+	if *keyFile == "" {
+		*keyFile = filepath.Join(*dataDir, "key.pem")
+	}
+
+	var err error
+	store, err = OpenStore(*dataDir)
+	errorCheck(err)
+
+	tokens, err = OpenTokenStore(filepath.Join(*dataDir, "tokens.json"))
+	errorCheck(err)
+	// Basic auth users come from the environment so the
+	// passwords don't show up in the process list
+	basicUsers, err = parseBasicUsers(os.Getenv("TODO_BASIC_AUTH"))
+	errorCheck(err)
+	if !authEnabled() {
+		log.Println("no TODO_BASIC_AUTH users or tokens, so anyone who can reach",
+			*addr, "can read and change the to dos. The admin pages stay shut")
+	}
+
+	webhooks, err = OpenWebhooks(filepath.Join(*dataDir, "webhooks.json"))
+	errorCheck(err)
+	store.OnChange(webhooks.storeChanged(store))
+
+	key, err := loadKey(filepath.Join(*dataDir, "secret.key"))
+	errorCheck(err)
+	shares, err = OpenShares(filepath.Join(*dataDir, "shares.json"), key)
+	errorCheck(err)
+
+	if *newToken != "" {
+		secret, _, err := tokens.Create(*newToken, "command line", *tokenScope)
+		errorCheck(err)
+		fmt.Println(secret)
+		return
+	}
+	if *restore != "" {
+		errorCheck(store.Restore(*restore, backups.dir))
+		fmt.Println("Restored", *restore)
+		return
+	}
+	errorCheck(store.RemoveOrphanedAttachments())
+	if backups.interval > 0 {
+		go runBackups(store, backups, nil)
+	}
+
 	// Our app is available at directory
-	// hello for the localhost port 8080
+	// greet for the localhost port 8080
 	// When it receives a request it calls
-	// the correct Handler
-	http.HandleFunc("/hello", englishHandler)
-	http.HandleFunc("/hola", spanishHandler)
-	http.HandleFunc("/bonjour", frenchHandler)
-	http.HandleFunc("/interact", interactHandler)
-	http.HandleFunc("/new", newHandler)
-	http.HandleFunc("/create", createHandler)
+	// the correct Handler from the route table
+	registerRoutes(http.DefaultServeMux)
+
+	// Every response gets the security headers and
+	// every request body is capped. Forms that take
+	// uploads get room for a few files
+	bodyLimits["/create"] = *maxBody + 4*uploads.maxSize
+	bodyLimits["/update"] = *maxBody + 4*uploads.maxSize
+	handler := secureHeaders(limitBody(*maxBody, http.DefaultServeMux))
+	server := newServer(*addr, handler)
 
 	// Listens for browser requests and responds
 	// Only receives a value if there is an error
-	err := http.ListenAndServe("localhost:8080", nil)
+	if *useTLS {
+		errorCheck(ensureCertificate(*certFile, *keyFile))
+		err = server.ListenAndServeTLS(*certFile, *keyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
 	log.Fatal(err)
 }
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The events a webhook can subscribe to
+const (
+	EventCreated   = "todo.created"
+	EventCompleted = "todo.completed"
+	EventPing      = "ping"
+)
+
+// Subscription sends events to a URL. Each request is
+// signed with the subscription's secret
+type Subscription struct {
+	ID      string    `json:"id"`
+	URL     string    `json:"url"`
+	Secret  string    `json:"secret"`
+	Events  []string  `json:"events"`
+	Created time.Time `json:"created"`
+}
+
+// WebhookEvent is the JSON body that is posted
+type WebhookEvent struct {
+	ID   string    `json:"id"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	List string    `json:"list,omitempty"`
+	ToDo *Item     `json:"todo,omitempty"`
+}
+
+// Delivery is one entry in the delivery log
+type Delivery struct {
+	Subscription string
+	Event        string
+	EventID      string
+	Attempts     int
+	Status       int
+	Error        string
+	Delivered    bool
+	Time         time.Time
+}
+
+// Webhooks keeps the subscriptions in a JSON file and
+// delivers events to them in the background
+type Webhooks struct {
+	mu         sync.Mutex
+	path       string
+	subs       []Subscription
+	deliveries []*Delivery
+	client     *http.Client
+	now        func() time.Time
+	wg         sync.WaitGroup
+
+	// Failed deliveries are retried with the delay
+	// doubling after each attempt
+	maxAttempts int
+	backoff     time.Duration
+	sleep       func(time.Duration)
+}
+
+// How many deliveries the log remembers
+const deliveryLogSize = 100
+
+// The webhook subscriptions for the running server
+var webhooks *Webhooks
+
+// Load the subscriptions, starting empty if the file
+// is missing
+func OpenWebhooks(fileName string) (*Webhooks, error) {
+	w := &Webhooks{
+		path:        fileName,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		now:         time.Now,
+		maxAttempts: 5,
+		backoff:     time.Second,
+		sleep:       time.Sleep,
+	}
+	contents, err := os.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return w, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(contents, &w.subs)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", fileName, err)
+	}
+	return w, nil
+}
+
+// Subscribe a URL to some events
+func (w *Webhooks) Add(rawURL string, events []string) (Subscription, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return Subscription{}, fmt.Errorf("%q is not an http or https URL", rawURL)
+	}
+	if len(events) == 0 {
+		return Subscription{}, errors.New("pick at least one event")
+	}
+	for _, event := range events {
+		if event != EventCreated && event != EventCompleted {
+			return Subscription{}, fmt.Errorf("unknown event %q", event)
+		}
+	}
+	id, err := randomBytes(8)
+	if err != nil {
+		return Subscription{}, err
+	}
+	secret, err := randomBytes(32)
+	if err != nil {
+		return Subscription{}, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	sub := Subscription{
+		ID:      hex.EncodeToString(id),
+		URL:     target.String(),
+		Secret:  hex.EncodeToString(secret),
+		Events:  events,
+		Created: w.now(),
+	}
+	subs := append(append([]Subscription(nil), w.subs...), sub)
+	err = writeJSONFile(w.path, subs)
+	if err != nil {
+		return Subscription{}, err
+	}
+	w.subs = subs
+	return sub, nil
+}
+
+// Delete a subscription
+func (w *Webhooks) Remove(id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, sub := range w.subs {
+		if sub.ID == id {
+			subs := append(append([]Subscription(nil), w.subs[:i]...), w.subs[i+1:]...)
+			err := writeJSONFile(w.path, subs)
+			if err != nil {
+				return err
+			}
+			w.subs = subs
+			return nil
+		}
+	}
+	return errNotFound
+}
+
+// Return a copy of the subscriptions
+func (w *Webhooks) Subscriptions() []Subscription {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]Subscription(nil), w.subs...)
+}
+
+// Return the delivery log newest first
+func (w *Webhooks) Deliveries() []Delivery {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var list []Delivery
+	for i := len(w.deliveries) - 1; i >= 0; i-- {
+		list = append(list, *w.deliveries[i])
+	}
+	return list
+}
+
+// Send an event to every subscription that wants it
+func (w *Webhooks) Publish(event WebhookEvent) {
+	for _, sub := range w.Subscriptions() {
+		for _, wanted := range sub.Events {
+			if wanted == event.Type {
+				w.send(sub, event)
+				break
+			}
+		}
+	}
+}
+
+// Send a ping event to one subscription
+func (w *Webhooks) TestFire(id string) error {
+	for _, sub := range w.Subscriptions() {
+		if sub.ID == id {
+			w.send(sub, w.newEvent(EventPing, "", nil))
+			return nil
+		}
+	}
+	return errNotFound
+}
+
+// Create an event with a fresh id
+func (w *Webhooks) newEvent(eventType, list string, item *Item) WebhookEvent {
+	id, err := randomBytes(8)
+	if err != nil {
+		log.Println(err)
+	}
+	return WebhookEvent{ID: hex.EncodeToString(id), Type: eventType,
+		Time: w.now(), List: list, ToDo: item}
+}
+
+// Deliver an event in the background
+func (w *Webhooks) send(sub Subscription, event WebhookEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	delivery := &Delivery{Subscription: sub.ID, Event: event.Type,
+		EventID: event.ID, Time: w.now()}
+	w.mu.Lock()
+	w.deliveries = append(w.deliveries, delivery)
+	if len(w.deliveries) > deliveryLogSize {
+		w.deliveries = w.deliveries[len(w.deliveries)-deliveryLogSize:]
+	}
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		w.deliver(sub, event, body, delivery)
+	}()
+}
+
+// Post the body until it succeeds or we run out of
+// attempts
+func (w *Webhooks) deliver(sub Subscription, event WebhookEvent, body []byte, delivery *Delivery) {
+	delay := w.backoff
+	for attempt := 1; attempt <= w.maxAttempts; attempt++ {
+		if attempt > 1 {
+			w.sleep(delay)
+			delay *= 2
+		}
+		status, err := w.post(sub, event, body)
+
+		w.mu.Lock()
+		delivery.Attempts = attempt
+		delivery.Status = status
+		delivery.Time = w.now()
+		delivery.Error = ""
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		delivery.Delivered = err == nil
+		w.mu.Unlock()
+
+		if err == nil {
+			return
+		}
+	}
+	log.Printf("webhook %s gave up on event %s after %d attempts",
+		sub.ID, event.ID, w.maxAttempts)
+}
+
+// Make one signed POST and report the status code
+func (w *Webhooks) post(sub Subscription, event WebhookEvent, body []byte) (int, error) {
+	request, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Todo-Event", event.Type)
+	request.Header.Set("X-Todo-Delivery", event.ID)
+	request.Header.Set("X-Todo-Signature", signPayload(sub.Secret, body))
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("receiver answered %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+// Wait for the deliveries in flight to finish
+func (w *Webhooks) Wait() {
+	w.wg.Wait()
+}
+
+// Sign a body with HMAC-SHA256. Receivers compute the
+// same value with the secret and compare
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Turn store changes into webhook events
+func (w *Webhooks) storeChanged(s *Store) func(HistoryEntry) {
+	return func(entry HistoryEntry) {
+		var eventType string
+		switch entry.Action {
+		case "create":
+			eventType = EventCreated
+		case "bulk-" + BulkDone:
+			eventType = EventCompleted
+		default:
+			return
+		}
+		for _, id := range entry.IDs {
+			item, list, ok := s.Find(id)
+			if ok {
+				w.Publish(w.newEvent(eventType, list, &item))
+			}
+		}
+	}
+}
+
+// What the admin page shows
+type webhooksPage struct {
+	Subscriptions []Subscription
+	Deliveries    []Delivery
+	Events        []string
+}
+
+// GET shows the subscriptions and delivery log. POST
+// adds, deletes or test-fires a subscription
+func adminWebhooksHandler(writer http.ResponseWriter,
+	request *http.Request) {
+	if request.Method == http.MethodPost {
+		var err error
+		switch request.FormValue("action") {
+		case "add":
+			_, err = webhooks.Add(strings.TrimSpace(request.FormValue("url")),
+				request.Form["event"])
+		case "delete":
+			err = webhooks.Remove(request.FormValue("id"))
+		case "test":
+			err = webhooks.TestFire(request.FormValue("id"))
+		default:
+			err = errors.New("unknown action")
+		}
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Redirect(writer, request, "/admin/webhooks", http.StatusFound)
+		return
+	}
+
+	tmpl, err := template.ParseFiles("webhooks.html")
+	errorCheck(err)
+	err = tmpl.Execute(writer, webhooksPage{
+		Subscriptions: webhooks.Subscriptions(),
+		Deliveries:    webhooks.Deliveries(),
+		Events:        []string{EventCreated, EventCompleted},
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
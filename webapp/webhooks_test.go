@@ -0,0 +1,121 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A local receiver that fails the first few requests
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func (r *receiver) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	body, _ := io.ReadAll(request.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, request.Header.Clone())
+	if r.failures > 0 {
+		r.failures--
+		http.Error(writer, "try again", http.StatusInternalServerError)
+	}
+}
+
+// Open webhooks that retry without really sleeping
+func testWebhooks(t *testing.T) (*Webhooks, *[]time.Duration) {
+	t.Helper()
+	w, err := OpenWebhooks(filepath.Join(t.TempDir(), "webhooks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var delays []time.Duration
+	w.maxAttempts = 4
+	w.backoff = 10 * time.Millisecond
+	w.sleep = func(d time.Duration) { delays = append(delays, d) }
+	return w, &delays
+}
+
+func TestWebhookSignedDeliveryWithRetries(t *testing.T) {
+	recv := &receiver{failures: 2}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	w, delays := testWebhooks(t)
+	sub, err := w.Add(server.URL, []string{EventCreated, EventCompleted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := testStore(t)
+	s.OnChange(w.storeChanged(s))
+	if _, err := s.Add(defaultList, "Clean Room"); err != nil {
+		t.Fatal(err)
+	}
+	w.Wait()
+
+	if len(recv.bodies) != 3 {
+		t.Fatalf("receiver got %d requests, want 3", len(recv.bodies))
+	}
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}
+	if len(*delays) != 2 || (*delays)[0] != want[0] || (*delays)[1] != want[1] {
+		t.Errorf("backoff delays were %v, want %v", *delays, want)
+	}
+	last := recv.headers[2]
+	if got := last.Get("X-Todo-Event"); got != EventCreated {
+		t.Errorf("event header is %q", got)
+	}
+	if got, want := last.Get("X-Todo-Signature"), signPayload(sub.Secret, recv.bodies[2]); got != want {
+		t.Errorf("signature is %q, want %q", got, want)
+	}
+	deliveries := w.Deliveries()
+	if len(deliveries) != 1 || !deliveries[0].Delivered || deliveries[0].Attempts != 3 {
+		t.Errorf("delivery log is %+v", deliveries)
+	}
+}
+
+func TestWebhookGivesUpAndTestFire(t *testing.T) {
+	recv := &receiver{failures: 100}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	w, _ := testWebhooks(t)
+	sub, err := w.Add(server.URL, []string{EventCompleted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Not subscribed to created events
+	w.Publish(w.newEvent(EventCreated, defaultList, nil))
+	if err := w.TestFire(sub.ID); err != nil {
+		t.Fatal(err)
+	}
+	w.Wait()
+
+	if len(recv.bodies) != 4 {
+		t.Fatalf("receiver got %d requests, want 4", len(recv.bodies))
+	}
+	deliveries := w.Deliveries()
+	if len(deliveries) != 1 || deliveries[0].Delivered || deliveries[0].Status != 500 {
+		t.Errorf("delivery log is %+v", deliveries)
+	}
+	if deliveries[0].Event != EventPing {
+		t.Errorf("test fire sent %q", deliveries[0].Event)
+	}
+}
+
+func TestWebhookRejectsBadSubscriptions(t *testing.T) {
+	w, _ := testWebhooks(t)
+	if _, err := w.Add("ftp://example.com", []string{EventCreated}); err == nil {
+		t.Error("accepted an ftp URL")
+	}
+	if _, err := w.Add("http://example.com", []string{"todo.exploded"}); err == nil {
+		t.Error("accepted an unknown event")
+	}
+}